@@ -0,0 +1,121 @@
+// Package cache inspects and manages the local issue cache.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+)
+
+var (
+	syncCache  bool
+	clearCache bool
+	listIssues bool
+	showIssue  string
+)
+
+// Command is the cache subcommand.
+var Command = cli.Command{
+	Name:        "cache",
+	Description: "inspect the issue cache set with -cache",
+	Flags: func(fs *flag.FlagSet) {
+		fs.BoolVar(&syncCache, "sync", false, "download the issues updated since the last sync (requires JIRA_EMAIL and JIRA_TOKEN)")
+		fs.BoolVar(&clearCache, "clear", false, "empty the cache, before syncing if -sync is set")
+		fs.BoolVar(&listIssues, "list", false, "list the cached issues")
+		fs.StringVar(&showIssue, "show", "", "print the cached issue with this key, as returned by Jira")
+	},
+	Run: run,
+}
+
+// Summary describes the content of the cache.
+type Summary struct {
+	Path     string     `json:"path"`
+	BaseURL  string     `json:"base_url"`
+	LastSync *time.Time `json:"last_sync"`
+	Issues   int        `json:"issues"`
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	cache := cfg.IssueCache
+	if cache == nil {
+		return cli.UsageError(errors.New("no issue cache: set -cache or " + config.EnvCacheFile))
+	}
+
+	if clearCache {
+		cache.Clear()
+	}
+
+	if syncCache {
+		if err := cfg.Require(config.EnvJiraEmail, config.EnvJiraToken); err != nil {
+			return cli.UsageError(err)
+		}
+		jiraClient, err := cfg.JiraClient()
+		if err != nil {
+			return err
+		}
+		if err := cache.Sync(ctx, jiraClient); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case showIssue != "":
+		entry, ok := cache.Entry(showIssue)
+		if !ok {
+			return fmt.Errorf("issue %q is not in the cache", showIssue)
+		}
+		var issue any
+		if err := json.Unmarshal(entry.Issue, &issue); err != nil {
+			return fmt.Errorf("error decoding the cached issue %q: %w", showIssue, err)
+		}
+		return printJSON(issue)
+
+	case listIssues:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tUPDATED\tSTATUS\tSUMMARY")
+		for _, entry := range cache.Entries() {
+			var issue jira.Issue
+			if err := json.Unmarshal(entry.Issue, &issue); err != nil {
+				return fmt.Errorf("error decoding the cached issue %q: %w", entry.Key, err)
+			}
+			var status, summary string
+			if issue.Fields != nil {
+				summary = issue.Fields.Summary
+				if issue.Fields.Status != nil {
+					status = issue.Fields.Status.Name
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Key, entry.Updated, status, summary)
+		}
+		return w.Flush()
+
+	default:
+		summary := Summary{
+			Path:    cache.Path(),
+			BaseURL: cfg.Jira.BaseURL,
+			Issues:  len(cache.Entries()),
+		}
+		if lastSync := cache.LastSync(); !lastSync.IsZero() {
+			summary.LastSync = &lastSync
+		}
+		return printJSON(summary)
+	}
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("error encoding the output: %w", err)
+	}
+	return nil
+}
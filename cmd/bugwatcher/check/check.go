@@ -0,0 +1,104 @@
+// Package check verifies that the variables are syntactically correct, and
+// that the team roster is consistent. It prints a JSON report on standard
+// output and fails if any error was found.
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+// slackTimeout bounds each request to the Slack users.info API.
+const slackTimeout = 30 * time.Second
+
+var (
+	days        int
+	verifyJIRA  bool
+	verifySLACK bool
+)
+
+// Command is the check subcommand.
+var Command = cli.Command{
+	Name:        "check",
+	Description: "validate the team roster",
+	Env: []string{
+		config.EnvPeople,
+	},
+	Flags: func(fs *flag.FlagSet) {
+		fs.IntVar(&days, "days", 14, "number of days, starting today, in which at least one triager must be available every weekday")
+		fs.BoolVar(&verifyJIRA, "verify-jira", false, "check the Jira account IDs against the Jira user API (requires JIRA_EMAIL and JIRA_TOKEN)")
+		fs.BoolVar(&verifySLACK, "verify-slack", false, "check the Slack IDs against the Slack users.info API (requires SLACK_TOKEN)")
+	},
+	Run: run,
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	if verifyJIRA {
+		if err := cfg.Require(config.EnvJiraEmail, config.EnvJiraToken); err != nil {
+			return cli.UsageError(err)
+		}
+	}
+	if verifySLACK {
+		if err := cfg.Require(config.EnvSlackToken); err != nil {
+			return cli.UsageError(err)
+		}
+	}
+
+	report := Report{Problems: []Problem{}}
+
+	people, err := cfg.Team(team.Strict())
+	if err != nil {
+		report.add(severityError, "decode", "", "error loading team members: %v", err)
+		return printReport(&report)
+	}
+
+	report.People = len(people)
+	for _, p := range people {
+		if p.BugTriage {
+			report.Triagers++
+		}
+	}
+
+	checkIdentifiers(&report, people)
+	checkLeave(&report, people)
+	checkTriageCoverage(&report, people, time.Now(), days)
+	checkHolidays(&report, people, time.Now(), days)
+
+	if verifyJIRA {
+		jiraClient, err := cfg.JiraClient()
+		if err != nil {
+			return err
+		}
+		verifyJira(ctx, &report, jiraClient, people)
+	}
+
+	if verifySLACK {
+		verifySlack(ctx, &report, &http.Client{Timeout: slackTimeout}, cfg.Slack.APIURL, cfg.SlackToken, people)
+	}
+
+	return printReport(&report)
+}
+
+// printReport writes the report to standard output. It returns an error if
+// the report contains errors.
+func printReport(report *Report) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("error encoding the report: %w", err)
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("the roster contains %d errors", report.Errors)
+	}
+	return nil
+}
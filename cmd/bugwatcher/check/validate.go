@@ -0,0 +1,174 @@
+package check
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// Problem is one finding of the roster validation.
+type Problem struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Person   string `json:"person,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Report is the machine-readable outcome of the validation.
+type Report struct {
+	People   int       `json:"people"`
+	Triagers int       `json:"triagers"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Problems []Problem `json:"problems"`
+}
+
+func (r *Report) add(severity, check, person, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{
+		Severity: severity,
+		Check:    check,
+		Person:   person,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	switch severity {
+	case severityError:
+		r.Errors++
+	case severityWarning:
+		r.Warnings++
+	}
+}
+
+// slackIDPattern matches Slack user IDs. team.Load prepends an `@` to them.
+var slackIDPattern = regexp.MustCompile(`^@[UW][A-Z0-9]{6,}$`)
+
+// checkDuplicates reports the values of key that are shared by more than one
+// person.
+func checkDuplicates(report *Report, people []team.Person, check string, key func(team.Person) string) {
+	seen := make(map[string]string, len(people))
+	for _, p := range people {
+		k := key(p)
+		if k == "" || k == "@" {
+			continue
+		}
+		if other, ok := seen[k]; ok {
+			report.add(severityError, check, p.Kerberos, "%q is also used by %q", strings.TrimPrefix(k, "@"), other)
+			continue
+		}
+		seen[k] = p.Kerberos
+	}
+}
+
+func checkIdentifiers(report *Report, people []team.Person) {
+	checkDuplicates(report, people, "duplicate-kerberos", func(p team.Person) string { return p.Kerberos })
+	checkDuplicates(report, people, "duplicate-jira-account-id", func(p team.Person) string { return p.JiraAccountID })
+	checkDuplicates(report, people, "duplicate-slack-id", func(p team.Person) string { return p.Slack })
+
+	for _, p := range people {
+		if p.Kerberos == "" {
+			report.add(severityError, "missing-kerberos", "", "a person has no kerberos (slack_id: %q)", strings.TrimPrefix(p.Slack, "@"))
+		}
+		if p.BugTriage && p.JiraAccountID == "" {
+			report.add(severityError, "missing-jira-account-id", p.Kerberos, "bug_triage members must have a jira_account_id")
+		}
+		if !slackIDPattern.MatchString(p.Slack) {
+			report.add(severityError, "malformed-slack-id", p.Kerberos, "%q is not a Slack user ID", strings.TrimPrefix(p.Slack, "@"))
+		}
+	}
+}
+
+// checkLeave reports the leave ranges that do not end after they start, and
+// those that overlap. Overlaps are only errors between ranges declared in
+// YAML: the calendars commonly repeat or refine them.
+func checkLeave(report *Report, people []team.Person) {
+	for _, p := range people {
+		leave := make([]team.Leave, 0, len(p.Leave))
+		for _, l := range p.Leave {
+			if !l.End.After(l.Start) {
+				report.add(severityError, "leave-end-before-start", p.Kerberos, "leave ends (%s) before or when it starts (%s)%s", l.End.Format(time.DateTime), l.Start.Format(time.DateTime), origin(l))
+				continue
+			}
+			leave = append(leave, l)
+		}
+
+		sort.Slice(leave, func(i, j int) bool { return leave[i].Start.Before(leave[j].Start) })
+		for i := 1; i < len(leave); i++ {
+			if leave[i].Start.Before(leave[i-1].End) {
+				severity := severityError
+				if leave[i].Calendar != "" || leave[i-1].Calendar != "" {
+					severity = severityWarning
+				}
+				report.add(severity, "leave-overlap", p.Kerberos, "leave starting %s%s overlaps with leave starting %s%s", leave[i].Start.Format(time.DateTime), origin(leave[i]), leave[i-1].Start.Format(time.DateTime), origin(leave[i-1]))
+			}
+		}
+	}
+}
+
+// origin describes the calendar the leave comes from, if any.
+func origin(l team.Leave) string {
+	if l.Calendar == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (from the calendar %q)", l.Calendar)
+}
+
+// checkTriageCoverage reports the weekdays, in the next days, where no
+// triager is available. Availability is evaluated at noon in the timezone of
+// each triager.
+func checkTriageCoverage(report *Report, people []team.Person, now time.Time, days int) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for d := 0; d < days; d++ {
+		day := today.AddDate(0, 0, d)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+
+		var available bool
+		for _, p := range people {
+			if !p.BugTriage {
+				continue
+			}
+			noon := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, p.Location())
+			if p.IsAvailable(noon) {
+				available = true
+				break
+			}
+		}
+		if !available {
+			report.add(severityError, "no-triager-available", "", "no triager is available on %s", day.Format(time.DateOnly))
+		}
+	}
+}
+
+// checkHolidays reports the holiday calendars that do not list the public
+// holidays of the years of the next days: the triagers would be considered
+// available on their holidays.
+func checkHolidays(report *Report, people []team.Person, now time.Time, days int) {
+	years := []int{now.Year()}
+	if last := now.AddDate(0, 0, days).Year(); last != now.Year() {
+		years = append(years, last)
+	}
+	for _, p := range people {
+		if p.Holidays == "" {
+			continue
+		}
+		for _, year := range years {
+			covered, err := team.HolidayCalendarCovers(p.Holidays, year)
+			if err != nil {
+				report.add(severityError, "holidays", p.Kerberos, "%v", err)
+				break
+			}
+			if !covered {
+				report.add(severityError, "holidays-outdated", p.Kerberos, "the holiday calendar %q has no data for %d", p.Holidays, year)
+			}
+		}
+	}
+}
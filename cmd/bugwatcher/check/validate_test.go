@@ -0,0 +1,161 @@
+package check
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+// problems returns the severity and the check of each problem of the report.
+func problems(report Report) []string {
+	var got []string
+	for _, p := range report.Problems {
+		got = append(got, p.Severity+" "+p.Check)
+	}
+	return got
+}
+
+func load(t *testing.T, peopleYAML string) []team.Person {
+	t.Helper()
+	people, err := team.Load(strings.NewReader(peopleYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return people
+}
+
+func TestCheckIdentifiers(t *testing.T) {
+	for _, tc := range [...]struct {
+		name   string
+		people string
+		want   []string
+	}{
+		{"valid", `
+- kerberos: user1
+  jira_account_id: acct-1
+  slack_id: U0123ABCD
+  bug_triage: true
+- kerberos: user2
+  slack_id: W0123ABCD
+`, nil},
+		{"duplicates", `
+- kerberos: user1
+  jira_account_id: acct-1
+  slack_id: U0123ABCD
+- kerberos: user1
+  jira_account_id: acct-1
+  slack_id: U0123ABCD
+`, []string{"error duplicate-kerberos", "error duplicate-jira-account-id", "error duplicate-slack-id"}},
+		{"missing kerberos", `
+- slack_id: U0123ABCD
+`, []string{"error missing-kerberos"}},
+		{"triager without a Jira account ID", `
+- kerberos: user1
+  slack_id: U0123ABCD
+  bug_triage: true
+`, []string{"error missing-jira-account-id"}},
+		{"malformed Slack ID", `
+- kerberos: user1
+  slack_id: "@user1"
+- kerberos: user2
+`, []string{"error malformed-slack-id", "error malformed-slack-id"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var report Report
+			checkIdentifiers(&report, load(t, tc.people))
+			if got := problems(report); !slices.Equal(got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckLeave(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, tc := range [...]struct {
+		name  string
+		leave []team.Leave
+		want  []string
+	}{
+		{"valid", []team.Leave{{Start: day(19), End: day(20)}, {Start: day(20), End: day(21)}}, nil},
+		{"ends before it starts", []team.Leave{{Start: day(20), End: day(19)}}, []string{"error leave-end-before-start"}},
+		{"ends when it starts", []team.Leave{{Start: day(20), End: day(20)}}, []string{"error leave-end-before-start"}},
+		{"overlap", []team.Leave{{Start: day(19), End: day(22)}, {Start: day(21), End: day(23)}}, []string{"error leave-overlap"}},
+		{"overlap with a calendar", []team.Leave{{Start: day(19), End: day(22)}, {Start: day(21), End: day(23), Calendar: "pto.ics"}}, []string{"warning leave-overlap"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var report Report
+			checkLeave(&report, []team.Person{{Kerberos: "user1", Leave: tc.leave}})
+			if got := problems(report); !slices.Equal(got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckTriageCoverage(t *testing.T) {
+	// Friday
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	for _, tc := range [...]struct {
+		name   string
+		people string
+		days   int
+		want   []string
+	}{
+		{"covered", `
+- kerberos: user1
+  bug_triage: true
+`, 7, nil},
+		{"weekend only", `
+- kerberos: user1
+  bug_triage: true
+  leave:
+  - start: 2026-10-16
+    end: 2026-10-16
+`, 3, []string{"error no-triager-available"}},
+		{"on leave", `
+- kerberos: user1
+  bug_triage: true
+  leave:
+  - start: 2026-10-19
+    end: 2026-10-20
+- kerberos: user2
+  leave: []
+`, 7, []string{"error no-triager-available", "error no-triager-available"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var report Report
+			checkTriageCoverage(&report, load(t, tc.people), now, tc.days)
+			if got := problems(report); !slices.Equal(got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckHolidays(t *testing.T) {
+	for _, tc := range [...]struct {
+		name     string
+		holidays string
+		now      time.Time
+		want     []string
+	}{
+		{"covered", "fr", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), nil},
+		{"unknown calendar", "atlantis", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), []string{"error holidays"}},
+		{"outdated", "fr", time.Date(2040, 6, 1, 0, 0, 0, 0, time.UTC), []string{"error holidays-outdated"}},
+		{"next year outdated", "fr", time.Date(2039, 12, 25, 0, 0, 0, 0, time.UTC), []string{"error holidays-outdated", "error holidays-outdated"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var report Report
+			checkHolidays(&report, []team.Person{{Kerberos: "user1", Holidays: tc.holidays}}, tc.now, 14)
+			if got := problems(report); !slices.Equal(got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+// verifyJira checks that each Jira account ID exists and is active.
+func verifyJira(ctx context.Context, report *Report, jiraClient *jira.Client, people []team.Person) {
+	for _, p := range people {
+		if p.JiraAccountID == "" {
+			continue
+		}
+
+		user, res, err := jiraClient.User.GetByAccountIDWithContext(ctx, p.JiraAccountID)
+		switch {
+		case res != nil && res.StatusCode == http.StatusNotFound:
+			report.add(severityError, "unknown-jira-account-id", p.Kerberos, "Jira account ID %q not found", p.JiraAccountID)
+		case err != nil:
+			report.add(severityWarning, "jira-verification-failed", p.Kerberos, "failed to verify Jira account ID %q: %v", p.JiraAccountID, err)
+		case !user.Active:
+			report.add(severityError, "inactive-jira-account", p.Kerberos, "Jira account %q (%s) is not active", p.JiraAccountID, user.DisplayName)
+		}
+	}
+}
+
+// slackUsersInfoMethod is the Slack Web API method returning information
+// about a user.
+const slackUsersInfoMethod = "users.info"
+
+// verifySlack checks that each Slack ID is an existing, non-deleted user,
+// with the Slack Web API at apiURL.
+func verifySlack(ctx context.Context, report *Report, httpClient *http.Client, apiURL, slackToken string, people []team.Person) {
+	for _, p := range people {
+		slackID := strings.TrimPrefix(p.Slack, "@")
+		if slackID == "" {
+			continue
+		}
+
+		user, err := slackUsersInfo(ctx, httpClient, apiURL, slackToken, slackID)
+		switch {
+		case err != nil:
+			report.add(severityWarning, "slack-verification-failed", p.Kerberos, "failed to verify Slack ID %q: %v", slackID, err)
+		case !user.OK && user.Error == "user_not_found":
+			report.add(severityError, "unknown-slack-id", p.Kerberos, "Slack ID %q not found", slackID)
+		case !user.OK:
+			report.add(severityWarning, "slack-verification-failed", p.Kerberos, "failed to verify Slack ID %q: %s", slackID, user.Error)
+		case user.User.Deleted:
+			report.add(severityError, "deleted-slack-user", p.Kerberos, "Slack user %q (%s) is deactivated", slackID, user.User.Name)
+		}
+	}
+}
+
+type slackUsersInfoResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	User  struct {
+		Name    string `json:"name"`
+		Deleted bool   `json:"deleted"`
+	} `json:"user"`
+}
+
+func slackUsersInfo(ctx context.Context, httpClient *http.Client, apiURL, slackToken, slackID string) (slackUsersInfoResponse, error) {
+	var info slackUsersInfoResponse
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(apiURL, "/")+"/"+slackUsersInfoMethod+"?"+url.Values{"user": {slackID}}.Encode(), nil)
+	if err != nil {
+		return info, err
+	}
+	req.Header.Set("Authorization", "Bearer "+slackToken)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("unexpected status code %q", res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return info, fmt.Errorf("error decoding the response: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,38 @@
+package doctext
+
+import (
+	"fmt"
+
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
+)
+
+// triageCheck verifies one Triage condition.
+// Returns true if the issue is triaged according to that particular condition.
+// If triaged is false, msg contains the reason.
+// err is non-nil in case of failure.
+type triageCheck func(issue.Issue) (triaged bool, msg string, err error)
+
+func docTextCheck(i issue.Issue) (bool, string, error) {
+	// We must set the type and (optionally) the text for release notes. The
+	// text must always be set unless the type is "Release Note Not Required".
+	releaseNoteType, err := i.ReleaseNoteType()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to parse release note type for issue %s: %w", i.Key, err)
+	}
+	if releaseNoteType != "" {
+		releaseNoteText, err := i.ReleaseNoteText()
+		if err != nil {
+			return false, "", fmt.Errorf("failed to parse release note text for issue %s: %w", i.Key, err)
+		}
+
+		if releaseNoteType == fields.ReleaseNoteNotRequired {
+			return true, "", nil
+		}
+		if releaseNoteText != "" {
+			return true, "", nil
+		}
+	}
+
+	return false, "the Release Note Text is missing", nil
+}
@@ -0,0 +1,197 @@
+// Package doctext finds resolved bugs lacking a doc text, or breaking the rules
+// scoped to doctext, and posts a reminder to Slack. The rules that a bug
+// breaks are named in a Jira comment.
+package doctext
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/rules"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+	"github.com/shiftstack/bugwatcher/pkg/workpool"
+)
+
+const (
+	queryResolved = `AND status in ("Release Pending", Verified, ON_QA)`
+	// queryNoDocText narrows the search to the bugs that fail the
+	// built-in check, when no rule needs to see the others.
+	queryNoDocText = ` AND "Release Note Text" is EMPTY`
+)
+
+// now is replaced in tests, which check the age of the bugs in the reminders.
+var now = time.Now
+
+// Command is the doctext subcommand.
+var Command = cli.Command{
+	Name:        "doctext",
+	Description: "remind the assignees of resolved bugs lacking a doc text",
+	Env: []string{
+		config.EnvJiraEmail,
+		config.EnvJiraToken,
+		config.EnvPeople,
+	},
+	Run: run,
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	people, err := cfg.Team()
+	if err != nil {
+		return cli.ConfigError(err)
+	}
+
+	notifier, err := cfg.Notifier()
+	if err != nil {
+		return cli.UsageError(err)
+	}
+
+	jiraClient, err := cfg.JiraClient()
+	if err != nil {
+		return err
+	}
+
+	scopedRules := cfg.Rules.For(rules.ScopeDoctext)
+	registry, err := fields.Load(ctx, jiraClient, append([]string{fields.ReleaseNoteType, fields.ReleaseNoteText}, scopedRules.Fields()...)...)
+	if err != nil {
+		if errors.Is(err, fields.ErrNotFound) {
+			return cli.ConfigError(err)
+		}
+		return err
+	}
+
+	// The assignee is read to address the reminders, which detail the bugs.
+	searchFields := append([]string{query.FieldAssignee}, slack.BugFields...)
+	searchFields = append(searchFields, registry.IDs(fields.ReleaseNoteType, fields.ReleaseNoteText)...)
+	searchFields = append(searchFields, scopedRules.JiraFields(registry)...)
+
+	jql := cfg.Scope().JQL() + queryResolved
+	if len(scopedRules) == 0 {
+		jql += queryNoDocText
+	} else {
+		// The comments are read to not repeat the same one every run.
+		searchFields = append(searchFields, query.FieldComment)
+	}
+
+	triageChecks := []triageCheck{
+		docTextCheck,
+	}
+
+	var (
+		found         int
+		gotErrors     bool
+		commentErrors atomic.Bool
+	)
+	pool := workpool.New(ctx, cfg.Concurrency)
+	var needingAttention workpool.Results[jira.Issue]
+	for jiraIssue, err := range query.SearchIssues(ctx, jiraClient, jql, query.WithFields(searchFields...)) {
+		if err != nil {
+			// The reminders are still sent for the bugs found so far.
+			gotErrors = true
+			log.Printf("ERROR: %v", err)
+			break
+		}
+		found++
+		issue := issue.New(jiraIssue, registry)
+		pool.Go(func(ctx context.Context) error {
+			reasons := make([]string, 0, len(triageChecks)+len(scopedRules))
+
+			for _, check := range triageChecks {
+				triaged, msg, err := check(issue)
+				if err != nil {
+					log.Printf("WARNING: issue %s: DocText check failed: %v", issue.Key, err)
+					continue
+				}
+				if !triaged {
+					reasons = append(reasons, msg)
+				}
+			}
+
+			var broken []string
+			for _, rule := range scopedRules {
+				ok, msg, err := rule.Check(issue)
+				if err != nil {
+					log.Printf("WARNING: issue %s: DocText check failed: %v", issue.Key, err)
+					continue
+				}
+				if !ok {
+					broken = append(broken, msg)
+				}
+			}
+			reasons = append(reasons, broken...)
+
+			if len(reasons) > 0 {
+				log.Printf("INFO: %q needs attention because %s", issue.Key, reasons)
+				needingAttention.Add(issue.Issue)
+			}
+			if len(broken) > 0 {
+				if err := commentRules(ctx, jiraClient, issue.Issue, broken); err != nil {
+					log.Printf("ERROR: Failed to comment %q: %v", issue.Key, err)
+					commentErrors.Store(true)
+				}
+			}
+			return nil
+		})
+	}
+	if err := pool.Wait(); err != nil {
+		return err
+	}
+	gotErrors = gotErrors || commentErrors.Load()
+
+	issuesNeedingAttention := make(map[string][]jira.Issue)
+	for _, issue := range needingAttention.Values() {
+		var assignee string
+		if issue.Fields.Assignee != nil {
+			assignee = issue.Fields.Assignee.AccountID
+		}
+		issuesNeedingAttention[assignee] = append(issuesNeedingAttention[assignee], issue)
+	}
+
+	for assigneeAccountID, issues := range issuesNeedingAttention {
+		var slackId, user string
+		if person, ok := team.PersonByJiraAccountID(people, assigneeAccountID); ok {
+			slackId, user = person.Slack, person.Slack
+		} else {
+			slackId = cfg.Slack.TeamID
+		}
+
+		if _, err := notifier.Notify(ctx, slack.Notification{Message: notification(cfg.File, issues, slackId, now()), User: user}); err != nil {
+			gotErrors = true
+			log.Print(err)
+			continue
+		}
+	}
+
+	log.Printf("INFO: The query found %d bugs", found)
+
+	if gotErrors {
+		return cli.ErrFailed
+	}
+	return nil
+}
+
+// commentRules names the rules that the issue breaks in a Jira comment, unless
+// the latest comment already says the same.
+func commentRules(ctx context.Context, jiraClient *jira.Client, issue jira.Issue, broken []string) error {
+	var comment strings.Builder
+	comment.WriteString("This bug breaks the doc text rules:\n")
+	for _, msg := range broken {
+		comment.WriteString("* " + msg + "\n")
+	}
+
+	if c := issue.Fields.Comments; c != nil && len(c.Comments) > 0 && c.Comments[len(c.Comments)-1].Body == comment.String() {
+		return nil
+	}
+	return mutation.Comment(ctx, jiraClient, issue, comment.String())
+}
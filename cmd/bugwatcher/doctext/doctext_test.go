@@ -0,0 +1,136 @@
+package doctext
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/jiratest"
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+	"gopkg.in/yaml.v3"
+)
+
+const people = `
+- kerberos: alice
+  jira_account_id: acct-alice
+  slack_id: UALICE0001
+- kerberos: bob
+  jira_account_id: acct-bob
+  slack_id: UBOB000001
+`
+
+func newConfig(jiraServer *jiratest.Server, slackServer *slacktest.Server) *config.Config {
+	cfg := &config.Config{
+		File:      config.DefaultFile(),
+		SlackHook: slackServer.URL,
+		JiraEmail: "bugwatcher@example.com",
+		JiraToken: "token",
+		People:    people,
+	}
+	cfg.Jira.BaseURL = jiraServer.URL
+	return cfg
+}
+
+// keys returns the issue keys linked from the messages.
+func keys(messages []slacktest.Message) []string {
+	var keys []string
+	for _, m := range messages {
+		for _, link := range m.Links {
+			keys = append(keys, link.Text)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestRun(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		jiraServer := jiratest.NewServer(t, "testdata/resolved.json")
+		slackServer := slacktest.NewServer(t)
+		cfg := newConfig(jiraServer, slackServer)
+		cfg.Concurrency = concurrency
+
+		if err := run(context.Background(), cfg); err != nil {
+			t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+		}
+
+		if got := len(slackServer.Messages()); got != 3 {
+			t.Errorf("concurrency %d: expected 3 messages, got %d", concurrency, got)
+		}
+		for _, tc := range [...]struct {
+			mention string
+			keys    []string
+		}{
+			{"@UALICE0001", []string{"OCPBUGS-41", "OCPBUGS-42"}},
+			{"@UBOB000001", []string{"OCPBUGS-45"}},
+			{cfg.Slack.TeamID, []string{"OCPBUGS-46"}},
+		} {
+			if got := keys(slackServer.MessagesTo(tc.mention)); !slices.Equal(got, tc.keys) {
+				t.Errorf("concurrency %d: %s: expected links to %q, got %q", concurrency, tc.mention, tc.keys, got)
+			}
+		}
+	}
+}
+
+const typeRule = `
+- name: release-note-type
+  expr: release_note_type is not empty
+  message: the Release Note Type is missing
+  scope: [doctext]
+`
+
+func TestRunRules(t *testing.T) {
+	jiraServer := jiratest.NewServer(t, "testdata/resolved.json")
+	slackServer := slacktest.NewServer(t)
+	cfg := newConfig(jiraServer, slackServer)
+	if err := yaml.Unmarshal([]byte(typeRule), &cfg.Rules); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second run finds the same bugs, and must not repeat the comments.
+	for range 2 {
+		if err := run(context.Background(), cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, tc := range [...]struct {
+		key    string
+		broken bool
+	}{
+		{"OCPBUGS-41", true},
+		{"OCPBUGS-42", false},
+		{"OCPBUGS-43", false},
+		{"OCPBUGS-44", false},
+		{"OCPBUGS-45", true},
+		{"OCPBUGS-46", true},
+	} {
+		t.Run(tc.key, func(t *testing.T) {
+			issue, ok := jiraServer.Issue(tc.key)
+			if !ok {
+				t.Fatalf("issue not found")
+			}
+
+			var comments []string
+			if issue.Fields.Comments != nil {
+				for _, comment := range issue.Fields.Comments.Comments {
+					comments = append(comments, comment.Body)
+				}
+			}
+			if !tc.broken {
+				if len(comments) > 0 {
+					t.Errorf("unexpected comments: %q", comments)
+				}
+				return
+			}
+			if len(comments) != 1 {
+				t.Fatalf("expected one comment, got %q", comments)
+			}
+			if want := `the Release Note Type is missing (rule "release-note-type")`; !strings.Contains(comments[0], want) {
+				t.Errorf("expected the comment to contain %q, got %q", want, comments[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package doctext
+
+import (
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+)
+
+// notification returns the message for the issues, addressed to slackId.
+func notification(cfg config.File, issues []jira.Issue, slackId string, now time.Time) slack.Message {
+	bugs := make([]slack.Bug, 0, len(issues))
+	for _, issue := range issues {
+		bugs = append(bugs, slack.NewBug(issue, cfg.IssueURL(issue.Key)))
+	}
+	return slack.BugsMessage(fallback(cfg, issues, slackId), "<"+slackId+"> please check the Release Note Text of these bugs:", bugs, now)
+}
+
+// fallback returns the plain-text version of the notification.
+func fallback(cfg config.File, issues []jira.Issue, slackId string) string {
+	var notification strings.Builder
+	notification.WriteByte('<')
+	notification.WriteString(slackId)
+	notification.WriteString("> please check the Release Note Text of these bugs:")
+	for _, issue := range issues {
+		notification.WriteByte(' ')
+		notification.WriteString(slack.Link(cfg.IssueURL(issue.Key), issue.Key))
+	}
+	return notification.String()
+}
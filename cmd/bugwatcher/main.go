@@ -0,0 +1,34 @@
+// bugwatcher is a collection of jobs that assist the ShiftStack team in
+// triaging their bugs.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/cache"
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/check"
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/doctext"
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/posttriage"
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/pretriage"
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/serve"
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/triage"
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+)
+
+var commands = []cli.Command{
+	pretriage.Command,
+	triage.Command,
+	posttriage.Command,
+	doctext.Command,
+	check.Command,
+	cache.Command,
+	serve.Command,
+}
+
+func main() {
+	log.SetFlags(log.Ldate | log.Ltime | log.LUTC)
+
+	os.Exit(cli.Main(context.Background(), "bugwatcher", os.Args[1:], commands))
+}
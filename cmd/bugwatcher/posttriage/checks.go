@@ -0,0 +1,97 @@
+package posttriage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
+)
+
+// triageCheck verifies one Triage condition.
+// Returns true if the issue is triaged according to that particular condition.
+// If triaged is false, msg contains the reason.
+// err is non-nil in case of failure.
+type triageCheck func(issue.Issue) (triaged bool, msg string, err error)
+
+func isNotBug(i issue.Issue) bool {
+	// Taken from https://redhat.atlassian.net/rest/api/2/resolution
+	if i.Fields.Resolution != nil {
+		switch i.Fields.Resolution.Name {
+		case "Won't Do", "Cannot Reproduce", "Can't Do", "Duplicate", "Not a Bug", "Obsolete":
+			return true
+		}
+	}
+	return false
+}
+
+func priorityCheck(i issue.Issue) (bool, string, error) {
+	// If a bug has been closed as a non-bug, we shouldn't insist on a priority.
+	if isNotBug(i) {
+		return true, "", nil
+	}
+
+	if i.Fields.Priority == nil || i.Fields.Priority.Name == "Undefined" {
+		return false, "the Priority assessment is missing", nil
+	}
+	return true, "", nil
+}
+
+func releaseBlockerCheck(i issue.Issue) (bool, string, error) {
+	rb, err := i.ReleaseBlocker()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to parse Release Blocker: %s", err)
+	}
+	if rb == issue.ReleaseBlockerProposed {
+		return false, "the issue is a proposed release blocker", nil
+	}
+	return true, "", nil
+}
+
+func testCoverageCheck(i issue.Issue) (bool, string, error) {
+	// If a bug has been closed as a non-bug, there is nothing to test.
+	if isNotBug(i) {
+		return true, "", nil
+	}
+
+	tc, err := i.TestCoverage()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to parse Test Coverage: %s", err)
+	}
+	if tc == issue.TestCoverageNone {
+		return false, "the Test Coverage assessment is missing", nil
+	}
+	return true, "", nil
+}
+
+var pullRequestURL = regexp.MustCompile(`^https://github\.com/[^/]+/[^/]+/pull/[0-9]+`)
+
+// testPRCheck returns a check requiring that bugs with automated test
+// coverage link to a GitHub pull request.
+func testPRCheck(ctx context.Context, jiraClient *jira.Client) triageCheck {
+	return func(i issue.Issue) (bool, string, error) {
+		if isNotBug(i) {
+			return true, "", nil
+		}
+
+		tc, err := i.TestCoverage()
+		if err != nil {
+			return false, "", fmt.Errorf("failed to parse Test Coverage: %s", err)
+		}
+		if tc != issue.TestCoverageAutomated {
+			return true, "", nil
+		}
+
+		links, _, err := jiraClient.Issue.GetRemoteLinksWithContext(ctx, i.Key)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to fetch the links: %w", err)
+		}
+		for _, link := range *links {
+			if link.Object != nil && pullRequestURL.MatchString(link.Object.URL) {
+				return true, "", nil
+			}
+		}
+		return false, "the Test Coverage is automated (+) but no test pull request is linked", nil
+	}
+}
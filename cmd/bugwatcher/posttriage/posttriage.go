@@ -0,0 +1,127 @@
+// Package posttriage resets the Triaged keyword on bugs that still need
+// attention.
+package posttriage
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/rules"
+	"github.com/shiftstack/bugwatcher/pkg/workpool"
+)
+
+const queryTriaged = `AND labels = "Triaged"`
+
+var requireTestPR bool
+
+// Command is the posttriage subcommand.
+var Command = cli.Command{
+	Name:        "posttriage",
+	Description: "remove the Triaged label from the bugs that still need attention",
+	Env: []string{
+		config.EnvJiraEmail,
+		config.EnvJiraToken,
+	},
+	Flags: func(fs *flag.FlagSet) {
+		fs.BoolVar(&requireTestPR, "require-test-pr", false, "untriage the bugs with automated Test Coverage (+) that do not link to a GitHub pull request")
+	},
+	Run: run,
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	jiraClient, err := cfg.JiraClient()
+	if err != nil {
+		return err
+	}
+
+	scopedRules := cfg.Rules.For(rules.ScopePosttriage)
+	registry, err := fields.Load(ctx, jiraClient, append([]string{fields.ReleaseBlocker, fields.TestCoverage}, scopedRules.Fields()...)...)
+	if err != nil {
+		if errors.Is(err, fields.ErrNotFound) {
+			return cli.ConfigError(err)
+		}
+		return err
+	}
+
+	// The fields read by the checks. The Test Coverage is also read by
+	// testPRCheck, and the remote links are fetched separately.
+	searchFields := append([]string{
+		query.FieldPriority,
+		query.FieldResolution,
+	}, registry.IDs(fields.ReleaseBlocker, fields.TestCoverage)...)
+	searchFields = append(searchFields, scopedRules.JiraFields(registry)...)
+
+	triageChecks := []triageCheck{
+		priorityCheck,
+		releaseBlockerCheck,
+		testCoverageCheck,
+	}
+	if requireTestPR {
+		triageChecks = append(triageChecks, testPRCheck(ctx, jiraClient))
+	}
+	for _, rule := range scopedRules {
+		triageChecks = append(triageChecks, rule.Check)
+	}
+
+	var (
+		found     int
+		searchErr error
+	)
+	pool := workpool.New(ctx, cfg.Concurrency)
+	for jiraIssue, err := range query.SearchIssues(ctx, jiraClient, cfg.Scope().JQL()+queryTriaged, query.WithFields(searchFields...)) {
+		if err != nil {
+			// The bugs found so far are still processed.
+			searchErr = err
+			log.Printf("ERROR: %v", err)
+			break
+		}
+		found++
+		issue := issue.New(jiraIssue, registry)
+		pool.Go(func(ctx context.Context) error {
+			reasons := make([]string, 0, len(triageChecks))
+
+			for _, check := range triageChecks {
+				triaged, msg, err := check(issue)
+				if err != nil {
+					log.Printf("WARNING: issue %s: Triage check failed: %v", issue.Key, err)
+					continue
+				}
+				if !triaged {
+					reasons = append(reasons, msg)
+				}
+			}
+
+			if len(reasons) > 0 {
+				log.Printf("INFO: Untriaging %q because %s", issue.Key, reasons)
+
+				var comment strings.Builder
+				comment.WriteString("Removing the Triaged label because:\n")
+				for _, reason := range reasons {
+					comment.WriteString("* " + reason + "\n")
+				}
+
+				if err := untriage(ctx, jiraClient, issue.Issue, comment.String()); err != nil {
+					log.Printf("ERROR: Failed to untriage %q: %v", issue.Key, err)
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	err = pool.Wait()
+
+	log.Printf("INFO: The query found %d bugs", found)
+
+	if err != nil || searchErr != nil {
+		return cli.ErrFailed
+	}
+	return nil
+}
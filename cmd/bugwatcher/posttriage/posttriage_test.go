@@ -0,0 +1,95 @@
+package posttriage
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/jiratest"
+	"gopkg.in/yaml.v3"
+)
+
+const severityRule = `
+- name: severity
+  expr: severity is not empty unless resolution in (Duplicate, Not a Bug)
+  message: the Severity is missing
+  scope: [posttriage]
+`
+
+func TestRun(t *testing.T) {
+	server := jiratest.NewServer(t, "testdata/triaged.json")
+
+	cfg := &config.Config{
+		File:      config.DefaultFile(),
+		JiraEmail: "bugwatcher@example.com",
+		JiraToken: "token",
+	}
+	cfg.Jira.BaseURL = server.URL
+	if err := yaml.Unmarshal([]byte(severityRule), &cfg.Rules); err != nil {
+		t.Fatal(err)
+	}
+
+	requireTestPR = true
+	t.Cleanup(func() { requireTestPR = false })
+
+	if err := run(context.Background(), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tc := range [...]struct {
+		key     string
+		triaged bool
+		reasons []string
+	}{
+		{"OCPBUGS-1", true, nil},
+		{"OCPBUGS-2", false, []string{"the Priority assessment is missing"}},
+		{"OCPBUGS-3", false, []string{"the Test Coverage assessment is missing"}},
+		{"OCPBUGS-4", true, nil},
+		{"OCPBUGS-5", false, []string{"no test pull request is linked"}},
+		{"OCPBUGS-6", false, []string{"the issue is a proposed release blocker"}},
+		{"OCPBUGS-7", false, []string{`the Severity is missing (rule "severity")`}},
+	} {
+		t.Run(tc.key, func(t *testing.T) {
+			issue, ok := server.Issue(tc.key)
+			if !ok {
+				t.Fatalf("issue not found")
+			}
+			if triaged := slices.Contains(issue.Fields.Labels, "Triaged"); triaged != tc.triaged {
+				t.Errorf("expected Triaged to be %t, got labels %q", tc.triaged, issue.Fields.Labels)
+			}
+
+			var comments []string
+			if issue.Fields.Comments != nil {
+				for _, comment := range issue.Fields.Comments.Comments {
+					comments = append(comments, comment.Body)
+				}
+			}
+			if tc.triaged {
+				if len(comments) > 0 {
+					t.Errorf("unexpected comments: %q", comments)
+				}
+				return
+			}
+			if len(comments) != 1 {
+				t.Fatalf("expected one comment, got %q", comments)
+			}
+			for _, reason := range tc.reasons {
+				if !strings.Contains(comments[0], reason) {
+					t.Errorf("expected the comment to contain %q, got %q", reason, comments[0])
+				}
+			}
+		})
+	}
+
+	var labelUpdates int
+	for _, m := range server.Mutations() {
+		if m.Method == "PUT" {
+			labelUpdates++
+		}
+	}
+	if labelUpdates != 5 {
+		t.Errorf("expected 5 label updates, got %d", labelUpdates)
+	}
+}
@@ -0,0 +1,26 @@
+package posttriage
+
+import (
+	"context"
+	"fmt"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+)
+
+// untriage removes the Triage label and comments on the issue
+func untriage(ctx context.Context, jiraClient *jira.Client, issue jira.Issue, comment string) error {
+	// Remove the Triaged label
+	if err := mutation.RemoveLabels(ctx, jiraClient, issue, "Triaged", "triaged"); err != nil {
+		return fmt.Errorf("failed setting issue %q as non triaged: %w", issue.Key, err)
+	}
+
+	// Add an explanatory comment
+	if comment != "" {
+		if err := mutation.Comment(ctx, jiraClient, issue, comment); err != nil {
+			return fmt.Errorf("failed commenting issue %q: %w", issue.Key, err)
+		}
+	}
+
+	return nil
+}
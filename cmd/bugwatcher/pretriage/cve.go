@@ -1,15 +1,14 @@
-package main
+package pretriage
 
 import (
 	"fmt"
-	"strings"
+	"log"
+	"sort"
 
 	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
 )
 
-// CVEFieldID is the JIRA custom field ID for the CVE identifier
-const CVEFieldID = "customfield_10667"
-
 // CVEGroup represents a group of related CVE issues
 type CVEGroup struct {
 	CVEID     string
@@ -26,17 +25,12 @@ func isVulnerability(issue jira.Issue) bool {
 }
 
 // extractCVEID extracts the CVE identifier from an issue's custom field
-func extractCVEID(issue jira.Issue) string {
-	if issue.Fields == nil || issue.Fields.Unknowns == nil {
-		return ""
-	}
-
-	if cveValue, ok := issue.Fields.Unknowns[CVEFieldID]; ok {
-		if cveStr, ok := cveValue.(string); ok {
-			return strings.TrimSpace(cveStr)
-		}
+func extractCVEID(i issue.Issue) string {
+	cveID, err := i.CVEID()
+	if err != nil {
+		log.Printf("WARNING: issue %s: %v", i.Key, err)
 	}
-	return ""
+	return cveID
 }
 
 // extractComponent extracts the first component name from an issue
@@ -48,30 +42,41 @@ func extractComponent(issue jira.Issue) string {
 }
 
 // groupKey creates a unique key for grouping: "CVE-ID|Component"
-func groupKey(issue jira.Issue) string {
-	cveID := extractCVEID(issue)
-	component := extractComponent(issue)
+func groupKey(i issue.Issue) string {
+	cveID := extractCVEID(i)
+	component := extractComponent(i.Issue)
 	return fmt.Sprintf("%s|%s", cveID, component)
 }
 
 // GroupCVEIssues groups issues by CVE ID + Component
 // Returns a map where key is "CVE-ID|Component" and value is the CVEGroup
-func GroupCVEIssues(issues []jira.Issue) map[string]*CVEGroup {
+func GroupCVEIssues(issues []issue.Issue) map[string]*CVEGroup {
 	groups := make(map[string]*CVEGroup)
 
-	for _, issue := range issues {
-		key := groupKey(issue)
+	for _, i := range issues {
+		key := groupKey(i)
 
 		if groups[key] == nil {
 			groups[key] = &CVEGroup{
-				CVEID:     extractCVEID(issue),
-				Component: extractComponent(issue),
-				Issues:    []jira.Issue{issue},
+				CVEID:     extractCVEID(i),
+				Component: extractComponent(i.Issue),
+				Issues:    []jira.Issue{i.Issue},
 			}
 		} else {
-			groups[key].Issues = append(groups[key].Issues, issue)
+			groups[key].Issues = append(groups[key].Issues, i.Issue)
 		}
 	}
 
 	return groups
 }
+
+// sortedKeys returns the keys of the CVE groups in lexical order, so that the
+// assignment is reproducible.
+func sortedKeys(cveGroups map[string]*CVEGroup) []string {
+	keys := make([]string, 0, len(cveGroups))
+	for key := range cveGroups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,4 +1,4 @@
-package main
+package pretriage
 
 import (
 	jira "github.com/andygrunwald/go-jira"
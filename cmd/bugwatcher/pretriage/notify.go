@@ -0,0 +1,76 @@
+package pretriage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+)
+
+// notification returns the message assigning the triage of the issue to
+// slackId, with buttons if the interactive notifications are enabled.
+func notification(cfg config.File, issue jira.Issue, slackId string, now time.Time) slack.Message {
+	var notification strings.Builder
+	notification.WriteByte('<')
+	notification.WriteString(slackId)
+	notification.WriteString("> you have been assigned triage of this bug: ")
+	notification.WriteString(slack.Link(cfg.IssueURL(issue.Key), issue.Key))
+
+	bugs := []slack.Bug{slack.NewBug(issue, cfg.IssueURL(issue.Key))}
+	intro := "<" + slackId + "> you have been assigned triage of this bug:"
+	if cfg.Slack.Interactive {
+		return slack.InteractiveBugsMessage(notification.String(), intro, bugs, now, cfg.Slack.HandoffComponents)
+	}
+	return slack.BugsMessage(notification.String(), intro, bugs, now)
+}
+
+// cveGroupNotification creates a Slack message for a group of related CVE
+// issues. The issues are listed in a collapsible section.
+func cveGroupNotification(cfg config.File, group *CVEGroup, slackId string, now time.Time) slack.Message {
+	var notification strings.Builder
+	notification.WriteByte('<')
+	notification.WriteString(slackId)
+	notification.WriteString("> ")
+	notification.WriteString(" You have been assigned to triage this CVE group: ")
+
+	// Format: "CVE-2024-XXXX (Component): ISSUE-1 ISSUE-2 ISSUE-3"
+	notification.WriteString(group.CVEID)
+	notification.WriteString(" (")
+	notification.WriteString(group.Component)
+	notification.WriteString("): ")
+
+	bugs := make([]slack.Bug, 0, len(group.Issues))
+	for i, issue := range group.Issues {
+		if i > 0 {
+			notification.WriteByte(' ')
+		}
+		notification.WriteString(slack.Link(cfg.IssueURL(issue.Key), issue.Key))
+		bugs = append(bugs, slack.NewBug(issue, cfg.IssueURL(issue.Key)))
+	}
+
+	if len(group.Issues) > 1 {
+		notification.WriteString(fmt.Sprintf(" (%d related issues)", len(group.Issues)))
+	}
+
+	intro := fmt.Sprintf("<%s> you have been assigned to triage this CVE group: *%s* (%s), %d issues",
+		slackId, slack.Escape(group.CVEID), slack.Escape(group.Component), len(group.Issues))
+	return slack.Message{
+		Text:   notification.String(),
+		Blocks: []slack.Block{slack.Section(intro), slack.BugList(bugs, now)},
+	}
+}
+
+// cveGroupFailureNotification lists the issues of a CVE group that could not
+// be assigned.
+func cveGroupFailureNotification(cfg config.File, keys []string) slack.Message {
+	var notification strings.Builder
+	notification.WriteString("These issues of the group could not be assigned, please assign them manually:")
+	for _, key := range keys {
+		notification.WriteByte(' ')
+		notification.WriteString(slack.Link(cfg.IssueURL(key), key))
+	}
+	return slack.Message{Text: notification.String()}
+}
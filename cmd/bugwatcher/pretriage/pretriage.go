@@ -0,0 +1,299 @@
+// Package pretriage finds untriaged, unassigned ShiftStack bugs and assigns
+// them to a team member.
+package pretriage
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+	"github.com/shiftstack/bugwatcher/pkg/workpool"
+)
+
+const queryARTReconciliation = `AND labels in ("art:reconciliation")
+	AND (
+		priority is EMPTY OR
+		"Release Note Type" is EMPTY OR
+		"Test Coverage" is EMPTY
+	)
+`
+
+var assignmentStrategy string
+
+// now returns the current time; tests replace it to control the availability
+// of the triagers.
+var now = time.Now
+
+// Command is the pretriage subcommand.
+var Command = cli.Command{
+	Name:        "pretriage",
+	Description: "assign the untriaged bugs to a team member",
+	Env: []string{
+		config.EnvJiraEmail,
+		config.EnvJiraToken,
+		config.EnvJiraAccountID,
+		config.EnvPeople,
+	},
+	Flags: func(fs *flag.FlagSet) {
+		defaultStrategy := os.Getenv("ASSIGNMENT_STRATEGY")
+		if defaultStrategy == "" {
+			defaultStrategy = "least-loaded"
+		}
+		fs.StringVar(&assignmentStrategy, "strategy", defaultStrategy, "assignment strategy: least-loaded, component-affinity, round-robin or random (or set ASSIGNMENT_STRATEGY)")
+	},
+	Run: run,
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	if !isStrategy(assignmentStrategy) {
+		return cli.UsageError(fmt.Errorf("unknown assignment strategy %q", assignmentStrategy))
+	}
+
+	scope := cfg.Scope().JQL()
+	queryUntriaged := scope + `AND ( assignee is EMPTY OR assignee = "` + cfg.JiraAccountID + `" ) AND (labels not in ("Triaged") OR labels is EMPTY)`
+
+	var people, triagers []team.Person
+	{
+		var err error
+		people, err = cfg.Team()
+		if err != nil {
+			return cli.ConfigError(err)
+		}
+
+		triagers = make([]team.Person, 0, len(people))
+
+		var onDuty int
+		now := now()
+		for _, p := range people {
+			if !p.BugTriage {
+				continue
+			}
+			onDuty++
+			if p.IsAvailable(now) {
+				triagers = append(triagers, p)
+			} else {
+				log.Printf("Triager %q is not available right now", p.Kerberos)
+			}
+		}
+		if onDuty < 1 {
+			return cli.ConfigError(fmt.Errorf("no triagers in the team"))
+		}
+	}
+
+	notifier, err := cfg.Notifier()
+	if err != nil {
+		return cli.UsageError(err)
+	}
+
+	jiraClient, err := cfg.JiraClient()
+	if err != nil {
+		return err
+	}
+
+	registry, err := fields.Load(ctx, jiraClient, fields.ReleaseNoteType, fields.TestCoverage, fields.CVE)
+	if err != nil {
+		if errors.Is(err, fields.ErrNotFound) {
+			return cli.ConfigError(err)
+		}
+		return err
+	}
+
+	log.Print("pre-setting any necessary fields for the ART reconciliation bugs...")
+	pool := workpool.New(ctx, cfg.Concurrency)
+	var gotErrors bool
+	// The updates only need the keys of the issues, which are returned with
+	// any field.
+	for issue, err := range query.SearchIssues(ctx, jiraClient, scope+queryARTReconciliation, query.WithFields(query.FieldPriority)) {
+		if err != nil {
+			gotErrors = true
+			log.Print(err)
+			break
+		}
+		pool.Go(func(ctx context.Context) error {
+			log.Printf("Updating issue %q", issue.Key)
+
+			// These changes are idempotent, so we don't need to check for the current value
+			updates := map[string]any{
+				"update": map[string]any{
+					"priority": []map[string]any{
+						{
+							"set": map[string]any{"name": "Normal"},
+						},
+					},
+					registry.ID(fields.ReleaseNoteType): []map[string]any{
+						{
+							"set": map[string]any{"value": fields.ReleaseNoteNotRequired},
+						},
+					},
+					registry.ID(fields.TestCoverage): []map[string]any{
+						{
+							"set": []map[string]any{
+								{"value": fields.TestCoverageManual},
+							},
+						},
+					},
+				},
+			}
+			if err := mutation.Update(ctx, jiraClient, issue, updates); err != nil {
+				log.Print(err)
+				return err
+			}
+			return nil
+		})
+	}
+	if err := pool.Wait(); err != nil || gotErrors {
+		return cli.ErrFailed
+	}
+
+	if len(triagers) < 1 {
+		return errors.New("no triagers available right now: leaving the untriaged bugs for the next run")
+	}
+
+	log.Printf("Preparing the %q assignment strategy...", assignmentStrategy)
+	strategy, err := newAssignmentStrategy(ctx, assignmentStrategy, jiraClient, scope, triagers)
+	if err != nil {
+		return fmt.Errorf("error preparing the assignment strategy: %w", err)
+	}
+
+	log.Print("Running the actual triage assignment...")
+
+	// Collect all issues first, separating CVEs from regular bugs
+	var cveIssues []issue.Issue
+	var regularIssues []jira.Issue
+
+	// The type and the CVE ID identify the CVE groups; the component and the
+	// links to the parent of backports drive the assignment; the
+	// notifications detail the bugs.
+	untriagedFields := query.WithFields(append([]string{
+		query.FieldIssueType,
+		query.FieldComponents,
+		query.FieldIssueLinks,
+		registry.ID(fields.CVE),
+	}, slack.BugFields...)...)
+	for jiraIssue, err := range query.SearchIssues(ctx, jiraClient, queryUntriaged, untriagedFields) {
+		if err != nil {
+			// Assigning a partial set of bugs would skew the balance between
+			// the triagers: leave them all for the next run.
+			return fmt.Errorf("error searching the untriaged bugs: %w", err)
+		}
+		if isVulnerability(jiraIssue) {
+			cveIssues = append(cveIssues, issue.New(jiraIssue, registry))
+		} else {
+			regularIssues = append(regularIssues, jiraIssue)
+		}
+	}
+
+	// Process CVE issues: group by CVE ID + Component, assign group together
+	if len(cveIssues) > 0 {
+		log.Printf("Found %d CVE issues, grouping...", len(cveIssues))
+		cveGroups := GroupCVEIssues(cveIssues)
+		log.Printf("Grouped into %d CVE groups", len(cveGroups))
+
+		for _, key := range sortedKeys(cveGroups) {
+			group := cveGroups[key]
+			assignee, reason, err := strategy.Assign(ctx, Work{CVEGroup: group}, triagers)
+			if err != nil {
+				gotErrors = true
+				log.Printf("error assigning CVE group %q: %v", key, err)
+				continue
+			}
+
+			log.Printf("Assigning CVE group %q (%d issues) to %q: %s",
+				key, len(group.Issues), assignee.Kerberos, reason)
+
+			// Assign all issues in the group to the same person
+			pool := workpool.New(ctx, cfg.Concurrency)
+			var failed workpool.Results[string]
+			for _, issue := range group.Issues {
+				pool.Go(func(ctx context.Context) error {
+					if err := mutation.Assign(ctx, jiraClient, issue, assignee.JiraAccountID); err != nil {
+						log.Print(err)
+						failed.Add(issue.Key)
+						return err
+					}
+					return nil
+				})
+			}
+			if err := pool.Wait(); err != nil {
+				gotErrors = true
+			}
+
+			// Send single grouped notification
+			posted, err := notifier.Notify(ctx, slack.Notification{
+				Message:   cveGroupNotification(cfg.File, group, assignee.Slack, now()),
+				User:      assignee.Slack,
+				Component: group.Component,
+			})
+			if err != nil {
+				gotErrors = true
+				log.Print(err)
+				continue
+			}
+
+			// The issues that could not be assigned are listed in the thread
+			// of the notification, with the Web API.
+			if failedKeys := failed.Values(); len(failedKeys) > 0 {
+				if _, err := notifier.Notify(ctx, slack.Notification{
+					Message:   cveGroupFailureNotification(cfg.File, failedKeys),
+					User:      assignee.Slack,
+					Component: group.Component,
+					Thread:    posted,
+				}); err != nil {
+					log.Print(err)
+				}
+			}
+		}
+	}
+
+	// Process regular bugs: the assignee is chosen sequentially, in the
+	// order returned by Jira, so that the assignment is reproducible.
+	pool = workpool.New(ctx, cfg.Concurrency)
+	for _, issue := range regularIssues {
+		assignee, reason, err := strategy.Assign(ctx, Work{Issue: &issue}, triagers)
+		if err != nil {
+			gotErrors = true
+			log.Printf("error assigning issue %q: %v", issue.Key, err)
+			continue
+		}
+
+		log.Printf("Assigning issue %q to %q: %s", issue.Key, assignee.Kerberos, reason)
+
+		pool.Go(func(ctx context.Context) error {
+			if err := mutation.Assign(ctx, jiraClient, issue, assignee.JiraAccountID); err != nil {
+				log.Print(err)
+				return err
+			}
+
+			if _, err := notifier.Notify(ctx, slack.Notification{
+				Message:   notification(cfg.File, issue, assignee.Slack, now()),
+				User:      assignee.Slack,
+				Component: extractComponent(issue),
+			}); err != nil {
+				log.Print(err)
+				return err
+			}
+			return nil
+		})
+	}
+	if err := pool.Wait(); err != nil {
+		gotErrors = true
+	}
+
+	if gotErrors {
+		return cli.ErrFailed
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package pretriage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/jiratest"
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+)
+
+const people = `
+- kerberos: alice
+  jira_account_id: acct-alice
+  slack_id: UALICE0001
+  bug_triage: true
+- kerberos: bob
+  jira_account_id: acct-bob
+  slack_id: UBOB000001
+  bug_triage: true
+- kerberos: carol
+  jira_account_id: acct-carol
+  slack_id: UCAROL0001
+`
+
+// filter emulates the queries of pretriage.
+func filter(jql string, issue jira.Issue) bool {
+	triaged := slices.Contains(issue.Fields.Labels, "Triaged")
+	switch {
+	case strings.Contains(jql, "art:reconciliation"):
+		return slices.Contains(issue.Fields.Labels, "art:reconciliation")
+	case strings.Contains(jql, "assignee is EMPTY"):
+		return issue.Fields.Assignee == nil && !triaged
+	case strings.Contains(jql, "resolution = Unresolved"):
+		return issue.Fields.Assignee != nil && !triaged
+	default:
+		return false
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := jiratest.NewServer(t, "testdata/untriaged.json")
+	server.Filter = filter
+
+	slackServer := slacktest.NewServer(t)
+
+	cfg := &config.Config{
+		File:          config.DefaultFile(),
+		SlackHook:     slackServer.URL,
+		JiraEmail:     "bugwatcher@example.com",
+		JiraToken:     "token",
+		JiraAccountID: "acct-bugwatcher",
+		People:        people,
+	}
+	cfg.Jira.BaseURL = server.URL
+
+	assignmentStrategy = "least-loaded"
+	now = func() time.Time { return time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { assignmentStrategy, now = "", time.Now })
+
+	if err := run(context.Background(), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, want := range map[string]string{
+		"OCPBUGS-11": "acct-alice",
+		"OCPBUGS-12": "acct-bob",
+		"OCPBUGS-13": "acct-alice",
+		"OCPBUGS-14": "acct-bob",
+		"OCPBUGS-15": "acct-bob",
+		"OCPBUGS-20": "acct-alice",
+	} {
+		issue, _ := server.Issue(key)
+		if issue.Fields.Assignee == nil || issue.Fields.Assignee.AccountID != want {
+			t.Errorf("%s: expected assignee %q, got %+v", key, want, issue.Fields.Assignee)
+		}
+	}
+
+	art, _ := server.Issue("OCPBUGS-11")
+	if art.Fields.Priority == nil || art.Fields.Priority.Name != "Normal" {
+		t.Errorf("OCPBUGS-11: expected priority Normal, got %+v", art.Fields.Priority)
+	}
+	if rnt, ok := art.Fields.Unknowns["customfield_10785"].(map[string]any); !ok || rnt["id"] != "12510" {
+		t.Errorf("OCPBUGS-11: expected Release Note Not Required, got %v", art.Fields.Unknowns["customfield_10785"])
+	}
+
+	// One message per regular bug, and one per CVE group.
+	if got := len(slackServer.Messages()); got != 4 {
+		t.Errorf("expected 4 Slack messages, got %d", got)
+	}
+	for mention, want := range map[string]int{"@UALICE0001": 2, "@UBOB000001": 2} {
+		if got := len(slackServer.MessagesTo(mention)); got != want {
+			t.Errorf("%s: expected %d messages, got %d", mention, want, got)
+		}
+	}
+	cveMessages := slackServer.MessagesTo("@UBOB000001")
+	if len(cveMessages) > 0 && len(cveMessages[0].Links) != 2 {
+		t.Errorf("expected the CVE group to be notified in one message, got %q", cveMessages[0].Text)
+	}
+	// The issues of the CVE group are listed in a collapsible section.
+	if len(cveMessages) > 0 {
+		blocks := cveMessages[0].Blocks
+		if len(blocks) != 2 || blocks[1]["type"] != "section" || blocks[1]["expand"] == true {
+			t.Errorf("expected the CVE group to be listed in a collapsible section, got %v", blocks)
+		} else if texts := cveMessages[0].BlockTexts(); strings.Count(texts[len(texts)-1], "\n") != 1 {
+			t.Errorf("expected one line per issue of the CVE group, got %q", texts[len(texts)-1])
+		}
+	}
+	for _, m := range slackServer.Messages() {
+		for _, link := range m.Links {
+			if want := cfg.IssueURL(link.Text); link.URL != want {
+				t.Errorf("expected the URL %q, got %q", want, link.URL)
+			}
+		}
+	}
+}
+
+func TestRoundRobinSearchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errorMessages":["invalid JQL"]}`, http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+	jiraClient, err := jira.NewClient(nil, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed search must not silently restart the rotation.
+	if _, err := newAssignmentStrategy(context.Background(), "round-robin", jiraClient, "project = OCPBUGS ", nil); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,306 @@
+package pretriage
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+// Work is the unit of triage assignment: either a single issue, or a group of
+// related CVE issues that go to the same person.
+type Work struct {
+	Issue    *jira.Issue
+	CVEGroup *CVEGroup
+}
+
+// Key identifies the work in logs.
+func (w Work) Key() string {
+	if w.CVEGroup != nil {
+		return w.CVEGroup.CVEID + "|" + w.CVEGroup.Component
+	}
+	return w.Issue.Key
+}
+
+// Component returns the Jira component of the work.
+func (w Work) Component() string {
+	if w.CVEGroup != nil {
+		return w.CVEGroup.Component
+	}
+	return extractComponent(*w.Issue)
+}
+
+// Issues returns all the Jira issues that are part of the work.
+func (w Work) Issues() []jira.Issue {
+	if w.CVEGroup != nil {
+		return w.CVEGroup.Issues
+	}
+	return []jira.Issue{*w.Issue}
+}
+
+// AssignmentStrategy decides who gets a piece of work among the available
+// triagers. triagers is never empty. The returned reason is a human-readable
+// explanation of the choice.
+type AssignmentStrategy interface {
+	Assign(ctx context.Context, work Work, triagers []team.Person) (assignee team.Person, reason string, err error)
+}
+
+// assignmentRecorder is implemented by the strategies that keep track of the
+// assignments made during the run. It is used by decorators to report the
+// assignments they decide without consulting the wrapped strategy.
+type assignmentRecorder interface {
+	Record(team.Person)
+}
+
+// isStrategy returns true if name is a valid assignment strategy.
+func isStrategy(name string) bool {
+	switch name {
+	case "random", "round-robin", "least-loaded", "component-affinity":
+		return true
+	}
+	return false
+}
+
+// newAssignmentStrategy returns the strategy with the given name, wrapped so
+// that backports go to the assignee of their parent, and so that the triagers
+// with declared expertise in the component of the work are preferred. scope is
+// the JQL selecting the team's bugs.
+func newAssignmentStrategy(ctx context.Context, name string, jiraClient *jira.Client, scope string, triagers []team.Person) (AssignmentStrategy, error) {
+	var strategy AssignmentStrategy
+	switch name {
+	case "random":
+		strategy = randomStrategy{}
+	case "round-robin":
+		rotation, err := newRoundRobinStrategy(ctx, jiraClient, scope, triagers)
+		if err != nil {
+			return nil, err
+		}
+		strategy = rotation
+	case "least-loaded":
+		load, err := newWorkload(ctx, jiraClient, scope, triagers)
+		if err != nil {
+			return nil, err
+		}
+		strategy = leastLoadedStrategy{load}
+	case "component-affinity":
+		load, err := newWorkload(ctx, jiraClient, scope, triagers)
+		if err != nil {
+			return nil, err
+		}
+		strategy = newComponentAffinityStrategy(jiraClient, scope, load)
+	default:
+		return nil, fmt.Errorf("unknown assignment strategy %q", name)
+	}
+	return backportStrategy{next: expertiseStrategy{strategy}, jiraClient: jiraClient}, nil
+}
+
+// randomStrategy picks a triager at random.
+type randomStrategy struct{}
+
+func (randomStrategy) Assign(_ context.Context, _ Work, triagers []team.Person) (team.Person, string, error) {
+	return triagers[rand.Intn(len(triagers))], "picked at random", nil
+}
+
+// roundRobinStrategy cycles through the triagers in Kerberos order. The
+// rotation resumes after the assignee of the most recently created ShiftStack
+// bug, so that consecutive runs don't all start from the same person.
+type roundRobinStrategy struct {
+	sync.Mutex
+	last string
+}
+
+// newRoundRobinStrategy queries Jira for the assignee of the most recently
+// created bug, to resume the rotation after them.
+func newRoundRobinStrategy(ctx context.Context, jiraClient *jira.Client, scope string, triagers []team.Person) (*roundRobinStrategy, error) {
+	s := new(roundRobinStrategy)
+
+	queryLast := scope + `AND assignee in (` + jqlAccountIDs(triagers) + `) ORDER BY created DESC`
+	issues, _, err := jiraClient.Issue.SearchV2JQLWithContext(ctx, queryLast, &jira.SearchOptionsV2{MaxResults: 1, Fields: []string{query.FieldAssignee}})
+	if err != nil {
+		return nil, fmt.Errorf("error finding the last assignee of the rotation: %w", err)
+	}
+	if len(issues) > 0 && issues[0].Fields.Assignee != nil {
+		if p, ok := team.PersonByJiraAccountID(triagers, issues[0].Fields.Assignee.AccountID); ok {
+			s.last = p.Kerberos
+		}
+	}
+	return s, nil
+}
+
+func (s *roundRobinStrategy) Assign(_ context.Context, _ Work, triagers []team.Person) (team.Person, string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	triagers = byKerberos(triagers)
+	next := triagers[0]
+	for _, p := range triagers {
+		if p.Kerberos > s.last {
+			next = p
+			break
+		}
+	}
+	s.last = next.Kerberos
+	return next, "next in the rotation", nil
+}
+
+func (s *roundRobinStrategy) Record(p team.Person) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.last = p.Kerberos
+}
+
+// leastLoadedStrategy picks the triager with the lowest weighted load.
+type leastLoadedStrategy struct {
+	load *workload
+}
+
+func (s leastLoadedStrategy) Assign(_ context.Context, _ Work, triagers []team.Person) (team.Person, string, error) {
+	assignee := s.load.Next(triagers)
+	return assignee, fmt.Sprintf("least loaded triager (load %.1f)", s.load.Load(assignee)), nil
+}
+
+func (s leastLoadedStrategy) Record(p team.Person) {
+	s.load.Add(p)
+}
+
+// affinityWindow is the JQL relative date beyond which past assignments
+// don't count towards component affinity.
+const affinityWindow = "-180d"
+
+// componentAffinityStrategy picks, among the triagers who handled the most
+// bugs in the same component recently, the one with the lowest load. When
+// nobody has handled the component, it falls back to the least loaded
+// triager.
+type componentAffinityStrategy struct {
+	jiraClient *jira.Client
+	scope      string
+	load       *workload
+
+	sync.Mutex
+	// affinity counts the recent bugs by component, then by Jira account
+	// ID. It is populated lazily.
+	affinity map[string]map[string]int
+}
+
+func newComponentAffinityStrategy(jiraClient *jira.Client, scope string, load *workload) *componentAffinityStrategy {
+	return &componentAffinityStrategy{
+		jiraClient: jiraClient,
+		scope:      scope,
+		load:       load,
+		affinity:   make(map[string]map[string]int),
+	}
+}
+
+func (s *componentAffinityStrategy) componentAffinity(ctx context.Context, component string, triagers []team.Person) (map[string]int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if affinity, ok := s.affinity[component]; ok {
+		return affinity, nil
+	}
+
+	affinity := make(map[string]int)
+	queryComponent := s.scope + `AND component = ` + query.Quote(component) + ` AND assignee in (` + jqlAccountIDs(triagers) + `) AND created >= "` + affinityWindow + `"`
+	for issue, err := range query.SearchIssues(ctx, s.jiraClient, queryComponent, query.WithFields(query.FieldAssignee)) {
+		if err != nil {
+			return nil, fmt.Errorf("error computing the affinity with %q: %w", component, err)
+		}
+		if issue.Fields.Assignee != nil {
+			affinity[issue.Fields.Assignee.AccountID]++
+		}
+	}
+	s.affinity[component] = affinity
+	return affinity, nil
+}
+
+func (s *componentAffinityStrategy) Assign(ctx context.Context, work Work, triagers []team.Person) (team.Person, string, error) {
+	component := work.Component()
+	affinity, err := s.componentAffinity(ctx, component, triagers)
+	if err != nil {
+		return team.Person{}, "", err
+	}
+
+	var (
+		experts []team.Person
+		best    int
+	)
+	for _, p := range triagers {
+		switch n := affinity[p.JiraAccountID]; {
+		case n > best:
+			experts = []team.Person{p}
+			best = n
+		case n == best && n > 0:
+			experts = append(experts, p)
+		}
+	}
+
+	if len(experts) == 0 {
+		assignee := s.load.Next(triagers)
+		return assignee, fmt.Sprintf("nobody handled %q recently; least loaded triager (load %.1f)", component, s.load.Load(assignee)), nil
+	}
+
+	assignee := s.load.Next(experts)
+	return assignee, fmt.Sprintf("handled %d %q bugs recently (load %.1f)", best, component, s.load.Load(assignee)), nil
+}
+
+func (s *componentAffinityStrategy) Record(p team.Person) {
+	s.load.Add(p)
+}
+
+// expertiseStrategy narrows the candidates down to the triagers with the
+// highest declared expertise in the component of the work, then delegates the
+// choice to the next strategy. If no available triager knows about the
+// component, all of them are candidates.
+type expertiseStrategy struct {
+	next AssignmentStrategy
+}
+
+func (s expertiseStrategy) Assign(ctx context.Context, work Work, triagers []team.Person) (team.Person, string, error) {
+	experts := team.ExpertsOf(triagers, work.Component())
+	if len(experts) == 0 {
+		return s.next.Assign(ctx, work, triagers)
+	}
+
+	assignee, reason, err := s.next.Assign(ctx, work, experts)
+	if err != nil {
+		return assignee, reason, err
+	}
+	return assignee, fmt.Sprintf("knows about %q; %s", work.Component(), reason), nil
+}
+
+func (s expertiseStrategy) Record(p team.Person) {
+	if r, ok := s.next.(assignmentRecorder); ok {
+		r.Record(p)
+	}
+}
+
+// backportStrategy assigns backports to the assignee of their parent bug, if
+// they are an available triager. Everything else is delegated to the next
+// strategy.
+type backportStrategy struct {
+	next       AssignmentStrategy
+	jiraClient *jira.Client
+}
+
+func (s backportStrategy) Assign(ctx context.Context, work Work, triagers []team.Person) (team.Person, string, error) {
+	if work.Issue != nil {
+		parent, isBackport, err := backportParent(s.jiraClient, *work.Issue)
+		if err != nil {
+			return team.Person{}, "", err
+		}
+		if isBackport && parent.Fields.Assignee != nil {
+			if p, ok := team.PersonByJiraAccountID(triagers, parent.Fields.Assignee.AccountID); ok {
+				if r, ok := s.next.(assignmentRecorder); ok {
+					r.Record(p)
+				}
+				return p, fmt.Sprintf("backport of %s, which is assigned to them", parent.Key), nil
+			}
+		}
+	}
+	return s.next.Assign(ctx, work, triagers)
+}
@@ -0,0 +1,113 @@
+package pretriage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+const (
+	// weightOpen is the load carried by each open, untriaged bug currently
+	// assigned to a triager.
+	weightOpen = 1.0
+
+	// weightRecent is the load carried by each bug that was assigned to a
+	// triager within recentWindow, regardless of its current state.
+	weightRecent = 0.5
+
+	// recentWindow is the JQL relative date defining "recent" assignments.
+	recentWindow = "-7d"
+)
+
+// workload keeps track of the weighted triage load of each triager, and hands
+// out new bugs to whoever has the least on their plate.
+type workload struct {
+	sync.Mutex
+	load map[string]float64
+}
+
+// newWorkload queries Jira for the open untriaged bugs and the recent
+// assignments of each triager.
+func newWorkload(ctx context.Context, jiraClient *jira.Client, scope string, triagers []team.Person) (*workload, error) {
+	w := &workload{
+		load: make(map[string]float64, len(triagers)),
+	}
+
+	queryOpen := scope + `AND assignee in (` + jqlAccountIDs(triagers) + `) AND (labels not in ("Triaged") OR labels is EMPTY) AND resolution = Unresolved`
+	for issue, err := range query.SearchIssues(ctx, jiraClient, queryOpen, query.WithFields(query.FieldAssignee)) {
+		if err != nil {
+			return nil, fmt.Errorf("error counting the open bugs: %w", err)
+		}
+		if issue.Fields.Assignee != nil {
+			w.load[issue.Fields.Assignee.AccountID] += weightOpen
+		}
+	}
+
+	for _, p := range triagers {
+		queryRecent := scope + `AND assignee changed to "` + p.JiraAccountID + `" after "` + recentWindow + `"`
+		for _, err := range query.SearchIssues(ctx, jiraClient, queryRecent, query.WithFields(query.FieldAssignee)) {
+			if err != nil {
+				return nil, fmt.Errorf("error counting the recent assignments of %q: %w", p.Kerberos, err)
+			}
+			w.load[p.JiraAccountID] += weightRecent
+		}
+	}
+
+	return w, nil
+}
+
+// Next returns the candidate with the lowest load, and accounts for the new
+// assignment. Ties are broken by Kerberos. candidates must not be empty.
+func (w *workload) Next(candidates []team.Person) team.Person {
+	w.Lock()
+	defer w.Unlock()
+
+	candidates = byKerberos(candidates)
+	next := candidates[0]
+	for _, p := range candidates[1:] {
+		if w.load[p.JiraAccountID] < w.load[next.JiraAccountID] {
+			next = p
+		}
+	}
+	w.load[next.JiraAccountID] += weightOpen
+	return next
+}
+
+// Add accounts for an assignment that was decided elsewhere.
+func (w *workload) Add(p team.Person) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.load[p.JiraAccountID] += weightOpen
+}
+
+// Load returns the current weighted load of the given triager.
+func (w *workload) Load(p team.Person) float64 {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.load[p.JiraAccountID]
+}
+
+// byKerberos returns a sorted copy of people.
+func byKerberos(people []team.Person) []team.Person {
+	sorted := make([]team.Person, len(people))
+	copy(sorted, people)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Kerberos < sorted[j].Kerberos })
+	return sorted
+}
+
+// jqlAccountIDs returns the Jira account IDs of people as a JQL list.
+func jqlAccountIDs(people []team.Person) string {
+	accountIDs := make([]string, len(people))
+	for i, p := range people {
+		accountIDs[i] = `"` + p.JiraAccountID + `"`
+	}
+	return strings.Join(accountIDs, ", ")
+}
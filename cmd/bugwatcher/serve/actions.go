@@ -0,0 +1,117 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+// triagedLabel marks the bugs that have been triaged.
+const triagedLabel = "Triaged"
+
+// handle applies the actions of the interaction. On success, the actions of
+// the bug are replaced with their outcome in the original message; on
+// failure, the error is shown to the user who interacted. Only the people of
+// the team can act on the bugs: anyone in the channel can click the buttons.
+func (h *handler) handle(ctx context.Context, interaction slack.Interaction) {
+	if interaction.Type != "block_actions" {
+		return
+	}
+
+	if _, ok := team.PersonBySlackID(h.people, interaction.User.ID); !ok {
+		log.Printf("Ignoring the interaction of %s, who is not in PEOPLE", interaction.User.ID)
+		refusal := slack.Message{Text: "Only the members of the team can act on these bugs: your Slack account is not listed in bugwatcher's PEOPLE."}
+		if err := h.slack.Respond(ctx, interaction.ResponseURL, refusal, false); err != nil {
+			log.Print(err)
+		}
+		return
+	}
+
+	for _, action := range interaction.Actions {
+		key, ok := slack.BugKey(action.BlockID)
+		if !ok {
+			continue
+		}
+
+		outcome, err := h.apply(ctx, key, action)
+		if err != nil {
+			log.Printf("Failed to apply %s to %s for %s: %v", action.ActionID, key, interaction.User.ID, err)
+			failure := slack.Message{Text: "Could not update " + key + ": " + slack.Escape(err.Error())}
+			if err := h.slack.Respond(ctx, interaction.ResponseURL, failure, false); err != nil {
+				log.Print(err)
+			}
+			continue
+		}
+
+		log.Printf("%s: %s by %s", key, outcome, interaction.User.ID)
+		outcome = fmt.Sprintf("*%s* %s by <@%s>", key, outcome, interaction.User.ID)
+		interaction.Message.Blocks = replaceBlock(interaction.Message.Blocks, action.BlockID, slack.Context(outcome))
+		if err := h.slack.Respond(ctx, interaction.ResponseURL, interaction.Message, true); err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+// apply changes the bug as requested by the action, and returns the outcome
+// in mrkdwn, e.g. "marked as triaged".
+func (h *handler) apply(ctx context.Context, key string, action slack.Action) (string, error) {
+	// Jira accepts the key wherever the ID of an issue is expected.
+	issue := jira.Issue{ID: key, Key: key}
+
+	switch action.ActionID {
+	case slack.ActionTriage:
+		return "marked as triaged", mutation.Update(ctx, h.jiraClient, issue, addLabel(triagedLabel))
+
+	case slack.ActionReassign:
+		person, ok := team.PersonBySlackID(h.people, action.SelectedUser)
+		if !ok || person.JiraAccountID == "" {
+			return "", fmt.Errorf("the Slack user %s has no Jira account ID in PEOPLE", action.SelectedUser)
+		}
+		return "reassigned to <" + person.Slack + ">", mutation.Assign(ctx, h.jiraClient, issue, person.JiraAccountID)
+
+	case slack.ActionSnooze:
+		until := now().AddDate(0, 0, query.SnoozeDays)
+		return "snoozed until " + until.UTC().Format("Monday, January 2"), mutation.Update(ctx, h.jiraClient, issue, addLabel(query.SnoozeLabel(until)))
+
+	case slack.ActionMoveComponent:
+		component := action.SelectedOption.Value
+		if !slices.Contains(h.cfg.Slack.HandoffComponents, component) {
+			return "", fmt.Errorf("%q is not one of the handoff components", component)
+		}
+		return "moved to " + slack.Escape(component), mutation.Update(ctx, h.jiraClient, issue, map[string]any{
+			"fields": map[string]any{
+				"components": []map[string]string{{"name": component}},
+			},
+		})
+
+	default:
+		return "", fmt.Errorf("unknown action %q", action.ActionID)
+	}
+}
+
+// addLabel returns the update payload adding the label to an issue.
+func addLabel(label string) map[string]any {
+	return map[string]any{
+		"update": map[string]any{
+			"labels": []map[string]string{{"add": label}},
+		},
+	}
+}
+
+// replaceBlock returns the blocks with the one identified by blockID replaced.
+func replaceBlock(blocks []slack.Block, blockID string, replacement slack.Block) []slack.Block {
+	blocks = slices.Clone(blocks)
+	for i := range blocks {
+		if blocks[i].BlockID == blockID {
+			blocks[i] = replacement
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,159 @@
+// Package serve handles the buttons of the interactive Slack notifications, by
+// applying the chosen changes to Jira.
+package serve
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+// InteractivityPath is the path of the Request URL to set in the
+// Interactivity settings of the Slack app.
+const InteractivityPath = "/slack/interactivity"
+
+const (
+	// maxBodySize is the maximum size of an interaction request.
+	maxBodySize = 1 << 20
+	// actionTimeout bounds the processing of an interaction, after Slack
+	// got its answer.
+	actionTimeout = time.Minute
+	// shutdownTimeout bounds the wait for the requests in progress on
+	// exit.
+	shutdownTimeout = 10 * time.Second
+)
+
+var listenAddress string
+
+// now returns the current time; tests replace it to sign the requests and to
+// date the snoozes.
+var now = time.Now
+
+// Command is the serve subcommand.
+var Command = cli.Command{
+	Name:        "serve",
+	Description: "apply the actions chosen with the buttons of the Slack notifications",
+	Env: []string{
+		config.EnvSlackSecret,
+		config.EnvJiraEmail,
+		config.EnvJiraToken,
+		config.EnvPeople,
+	},
+	Flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&listenAddress, "listen", ":8080", "address to listen on for the Slack interactivity requests, served at "+InteractivityPath)
+	},
+	Run: run,
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	people, err := cfg.Team()
+	if err != nil {
+		return cli.ConfigError(err)
+	}
+
+	jiraClient, err := cfg.JiraClient()
+	if err != nil {
+		return err
+	}
+
+	h := newHandler(cfg, jiraClient, people)
+	mux := http.NewServeMux()
+	mux.Handle("POST "+InteractivityPath, h)
+	server := &http.Server{
+		Addr:              listenAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+	log.Printf("Listening on %s", listenAddress)
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("error serving the interactivity requests: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Print("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err = server.Shutdown(shutdownCtx)
+	h.pending.Wait()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error shutting down: %w", err)
+	}
+	return nil
+}
+
+// handler answers the interaction requests sent by Slack.
+type handler struct {
+	cfg        config.File
+	secret     string
+	jiraClient *jira.Client
+	people     []team.Person
+	slack      slack.Client
+
+	// pending tracks the interactions being processed.
+	pending sync.WaitGroup
+}
+
+func newHandler(cfg *config.Config, jiraClient *jira.Client, people []team.Person) *handler {
+	return &handler{
+		cfg:        cfg.File,
+		secret:     cfg.SlackSecret,
+		jiraClient: jiraClient,
+		people:     people,
+		slack:      slack.New(),
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "error reading the request", http.StatusBadRequest)
+		return
+	}
+
+	if err := slack.VerifyRequest(h.secret, r.Header, body, now()); err != nil {
+		log.Printf("Rejected an interaction request from %s: %v", r.RemoteAddr, err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	interaction, err := slack.ParseInteraction(body)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects an answer within 3 seconds: the actions are applied
+	// afterwards, and their outcome is sent to the response URL.
+	h.pending.Add(1)
+	go func() {
+		defer h.pending.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), actionTimeout)
+		defer cancel()
+		h.handle(ctx, interaction)
+	}()
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,241 @@
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/jiratest"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+const (
+	secret = "slacktest-signing-secret"
+	people = `
+- kerberos: alice
+  jira_account_id: acct-alice
+  slack_id: UALICE0001
+- kerberos: bob
+  slack_id: UBOB000001
+`
+)
+
+// setup returns a handler acting on the Jira server, and the Slack server
+// receiving its responses.
+func setup(t *testing.T) (*handler, *jiratest.Server, *slacktest.Server) {
+	t.Helper()
+
+	now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	jiraServer := jiratest.NewServer(t, "testdata/issues.json")
+	slackServer := slacktest.NewServer(t)
+	cfg := &config.Config{
+		File:        config.DefaultFile(),
+		SlackSecret: secret,
+		JiraEmail:   "bugwatcher@example.com",
+		JiraToken:   "token",
+	}
+	cfg.Jira.BaseURL = jiraServer.URL
+	cfg.Slack.HandoffComponents = []string{"Networking / ovn-kubernetes"}
+
+	members, err := team.Load(strings.NewReader(people))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jiraClient, err := cfg.JiraClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newHandler(cfg, jiraClient, members), jiraServer, slackServer
+}
+
+// post sends a signed interaction of the user with the action on OCPBUGS-1,
+// and waits for it to be processed. It returns the status code of the
+// response.
+func post(t *testing.T, h *handler, slackServer *slacktest.Server, user string, action map[string]any, sign func(timestamp string, body []byte) string) int {
+	t.Helper()
+
+	message := slack.InteractiveBugsMessage("fallback", "<@UALICE0001> please triage these bugs:", []slack.Bug{
+		{Key: "OCPBUGS-1", URL: "https://jira.example.com/browse/OCPBUGS-1"},
+		{Key: "OCPBUGS-2", URL: "https://jira.example.com/browse/OCPBUGS-2"},
+	}, now(), []string{"Networking / ovn-kubernetes"})
+	action["block_id"] = "bugwatcher_bug:OCPBUGS-1"
+	payload, err := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]any{"id": user},
+		"response_url": slackServer.ResponseURL(),
+		"message":      map[string]any{"text": message.Text, "blocks": message.Blocks},
+		"actions":      []map[string]any{action},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(url.Values{"payload": {string(payload)}}.Encode())
+
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, InteractivityPath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", sign(timestamp, body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	h.pending.Wait()
+	return rec.Code
+}
+
+func signed(timestamp string, body []byte) string {
+	return slack.Signature(secret, timestamp, body)
+}
+
+func TestActions(t *testing.T) {
+	for _, tc := range [...]struct {
+		name    string
+		action  map[string]any
+		outcome string
+		check   func(t *testing.T, jiraServer *jiratest.Server)
+	}{
+		{
+			"triage",
+			map[string]any{"action_id": slack.ActionTriage, "value": "OCPBUGS-1"},
+			"*OCPBUGS-1* marked as triaged by <@UBOB000001>",
+			func(t *testing.T, jiraServer *jiratest.Server) {
+				issue, _ := jiraServer.Issue("OCPBUGS-1")
+				if !slices.Contains(issue.Fields.Labels, "Triaged") {
+					t.Errorf("expected the Triaged label, got %q", issue.Fields.Labels)
+				}
+			},
+		},
+		{
+			"reassign",
+			map[string]any{"action_id": slack.ActionReassign, "selected_user": "UALICE0001"},
+			"*OCPBUGS-1* reassigned to <@UALICE0001> by <@UBOB000001>",
+			func(t *testing.T, jiraServer *jiratest.Server) {
+				issue, _ := jiraServer.Issue("OCPBUGS-1")
+				if issue.Fields.Assignee == nil || issue.Fields.Assignee.AccountID != "acct-alice" {
+					t.Errorf("expected the bug to be assigned to alice, got %+v", issue.Fields.Assignee)
+				}
+			},
+		},
+		{
+			"snooze",
+			map[string]any{"action_id": slack.ActionSnooze, "value": "OCPBUGS-1"},
+			"*OCPBUGS-1* snoozed until Tuesday, October 20 by <@UBOB000001>",
+			func(t *testing.T, jiraServer *jiratest.Server) {
+				issue, _ := jiraServer.Issue("OCPBUGS-1")
+				if !slices.Contains(issue.Fields.Labels, "bugwatcher-snoozed-until-2026-10-20") {
+					t.Errorf("expected the snooze label, got %q", issue.Fields.Labels)
+				}
+			},
+		},
+		{
+			"move component",
+			map[string]any{"action_id": slack.ActionMoveComponent, "selected_option": map[string]any{"value": "Networking / ovn-kubernetes"}},
+			"*OCPBUGS-1* moved to Networking / ovn-kubernetes by <@UBOB000001>",
+			func(t *testing.T, jiraServer *jiratest.Server) {
+				issue, _ := jiraServer.Issue("OCPBUGS-1")
+				if len(issue.Fields.Components) != 1 || issue.Fields.Components[0].Name != "Networking / ovn-kubernetes" {
+					t.Errorf("expected the bug to be moved, got %+v", issue.Fields.Components)
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, jiraServer, slackServer := setup(t)
+
+			if code := post(t, h, slackServer, "UBOB000001", tc.action, signed); code != http.StatusOK {
+				t.Fatalf("expected the status 200, got %d", code)
+			}
+			tc.check(t, jiraServer)
+
+			// The actions of the bug are replaced with the outcome, and
+			// those of the other bugs are kept.
+			responses := slackServer.Responses()
+			if len(responses) != 1 || !responses[0].ReplaceOriginal {
+				t.Fatalf("expected the message to be replaced, got %+v", responses)
+			}
+			if texts := responses[0].BlockTexts(); !slices.Contains(texts, tc.outcome) {
+				t.Errorf("expected the blocks to contain %q, got %q", tc.outcome, texts)
+			}
+			var actions []string
+			for _, block := range responses[0].Blocks {
+				if block["type"] == "actions" {
+					actions = append(actions, block["block_id"].(string))
+				}
+			}
+			if !slices.Equal(actions, []string{"bugwatcher_bug:OCPBUGS-2"}) {
+				t.Errorf("expected only the actions of OCPBUGS-2 to remain, got %q", actions)
+			}
+		})
+	}
+}
+
+func TestActionFailure(t *testing.T) {
+	for _, tc := range [...]struct {
+		name   string
+		action map[string]any
+		want   string
+	}{
+		{"reassign to a stranger", map[string]any{"action_id": slack.ActionReassign, "selected_user": "UCAROL0001"}, "the Slack user UCAROL0001 has no Jira account ID"},
+		{"move to another component", map[string]any{"action_id": slack.ActionMoveComponent, "selected_option": map[string]any{"value": "Networking / kuryr"}}, "not one of the handoff components"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, jiraServer, slackServer := setup(t)
+
+			if code := post(t, h, slackServer, "UBOB000001", tc.action, signed); code != http.StatusOK {
+				t.Fatalf("expected the status 200, got %d", code)
+			}
+			if got := len(jiraServer.Mutations()); got != 0 {
+				t.Errorf("expected no change to Jira, got %d", got)
+			}
+			responses := slackServer.Responses()
+			if len(responses) != 1 || responses[0].ReplaceOriginal || !strings.Contains(responses[0].Text, tc.want) {
+				t.Errorf("expected an error shown to the user containing %q, got %+v", tc.want, responses)
+			}
+		})
+	}
+}
+
+func TestStranger(t *testing.T) {
+	h, jiraServer, slackServer := setup(t)
+
+	if code := post(t, h, slackServer, "UCAROL0001", map[string]any{"action_id": slack.ActionTriage, "value": "OCPBUGS-1"}, signed); code != http.StatusOK {
+		t.Fatalf("expected the status 200, got %d", code)
+	}
+	if got := len(jiraServer.Mutations()); got != 0 {
+		t.Errorf("expected no change to Jira, got %d", got)
+	}
+	responses := slackServer.Responses()
+	if len(responses) != 1 || responses[0].ReplaceOriginal || !strings.Contains(responses[0].Text, "not listed in bugwatcher's PEOPLE") {
+		t.Errorf("expected a refusal shown to the user, got %+v", responses)
+	}
+}
+
+func TestInvalidSignature(t *testing.T) {
+	h, jiraServer, slackServer := setup(t)
+
+	for _, sign := range []func(string, []byte) string{
+		func(timestamp string, body []byte) string { return slack.Signature("other", timestamp, body) },
+		func(string, []byte) string { return "" },
+	} {
+		if code := post(t, h, slackServer, "UBOB000001", map[string]any{"action_id": slack.ActionTriage}, sign); code != http.StatusUnauthorized {
+			t.Errorf("expected the status 401, got %d", code)
+		}
+	}
+	if got := len(jiraServer.Mutations()); got != 0 {
+		t.Errorf("expected no change to Jira, got %d", got)
+	}
+	if got := len(slackServer.Responses()); got != 0 {
+		t.Errorf("expected no response, got %d", got)
+	}
+}
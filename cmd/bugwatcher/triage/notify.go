@@ -0,0 +1,37 @@
+package triage
+
+import (
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+)
+
+// notification returns the message for the issues, addressed to slackId. The
+// bugs get buttons if the interactive notifications are enabled.
+func notification(cfg config.File, issues []jira.Issue, slackId string, now time.Time) slack.Message {
+	bugs := make([]slack.Bug, 0, len(issues))
+	for _, issue := range issues {
+		bugs = append(bugs, slack.NewBug(issue, cfg.IssueURL(issue.Key)))
+	}
+	intro := "<" + slackId + "> please triage these bugs:"
+	if cfg.Slack.Interactive {
+		return slack.InteractiveBugsMessage(fallback(cfg, issues, slackId), intro, bugs, now, cfg.Slack.HandoffComponents)
+	}
+	return slack.BugsMessage(fallback(cfg, issues, slackId), intro, bugs, now)
+}
+
+// fallback returns the plain-text version of the notification.
+func fallback(cfg config.File, issues []jira.Issue, slackId string) string {
+	var notification strings.Builder
+	notification.WriteByte('<')
+	notification.WriteString(slackId)
+	notification.WriteString("> please triage these bugs:")
+	for _, issue := range issues {
+		notification.WriteByte(' ')
+		notification.WriteString(slack.Link(cfg.IssueURL(issue.Key), issue.Key))
+	}
+	return notification.String()
+}
@@ -0,0 +1,105 @@
+// Package triage reminds assignees about the bugs assigned to them for triage.
+package triage
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/cmd/bugwatcher/triage/tasker"
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+	"github.com/shiftstack/bugwatcher/pkg/workpool"
+)
+
+const queryUntriaged = `AND (labels not in ("Triaged") OR labels is EMPTY) AND "Need Info From" is EMPTY`
+
+// now returns the current time; tests replace it to control the age of the
+// bugs in the notifications.
+var now = time.Now
+
+// Command is the triage subcommand.
+var Command = cli.Command{
+	Name:        "triage",
+	Description: "remind the assignees about the bugs assigned to them for triage",
+	Env: []string{
+		config.EnvJiraEmail,
+		config.EnvJiraToken,
+		config.EnvPeople,
+	},
+	Run: run,
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	people, err := cfg.Team()
+	if err != nil {
+		return cli.ConfigError(err)
+	}
+
+	notifier, err := cfg.Notifier()
+	if err != nil {
+		return cli.UsageError(err)
+	}
+
+	jiraClient, err := cfg.JiraClient()
+	if err != nil {
+		return err
+	}
+
+	var gotErrors bool
+	issuesByAssignee := new(tasker.Tasker)
+	pool := workpool.New(ctx, cfg.Concurrency)
+	// The reminders are grouped by assignee. The bugs snoozed from the
+	// interactive notifications are left out.
+	searchFields := append([]string{query.FieldAssignee}, slack.BugFields...)
+	for issue, err := range query.SearchIssues(ctx, jiraClient, cfg.Scope().JQL()+queryUntriaged+"\n"+query.NotSnoozed(now()), query.WithFields(searchFields...)) {
+		if err != nil {
+			// The reminders are still sent for the bugs found so far.
+			gotErrors = true
+			log.Print(err)
+			break
+		}
+		pool.Go(func(context.Context) error {
+			var assignee string
+			if issue.Fields.Assignee == nil {
+				assignee = "team"
+			} else {
+				assignee = issue.Fields.Assignee.AccountID
+			}
+			issuesByAssignee.Assign(assignee, issue)
+			return nil
+		})
+	}
+	if err := pool.Wait(); err != nil {
+		return err
+	}
+
+	for {
+		assignee, issues, ok := issuesByAssignee.Pop()
+		if !ok {
+			break
+		}
+
+		var slackId, user string
+		if person, ok := team.PersonByJiraAccountID(people, assignee); ok {
+			slackId, user = person.Slack, person.Slack
+		} else {
+			log.Printf("failed to find slack ID for team member %s", assignee)
+			slackId = cfg.Slack.TeamID
+		}
+
+		if _, err := notifier.Notify(ctx, slack.Notification{Message: notification(cfg.File, issues, slackId, now()), User: user}); err != nil {
+			gotErrors = true
+			log.Print(err)
+			continue
+		}
+	}
+
+	if gotErrors {
+		return cli.ErrFailed
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package triage
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/cli"
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/jiratest"
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+)
+
+const people = `
+- kerberos: alice
+  jira_account_id: acct-alice
+  slack_id: UALICE0001
+- kerberos: bob
+  jira_account_id: acct-bob
+  slack_id: UBOB000001
+`
+
+func newConfig(jiraServer *jiratest.Server, slackServer *slacktest.Server) *config.Config {
+	cfg := &config.Config{
+		File:      config.DefaultFile(),
+		SlackHook: slackServer.URL,
+		JiraEmail: "bugwatcher@example.com",
+		JiraToken: "token",
+		People:    people,
+	}
+	cfg.Jira.BaseURL = jiraServer.URL
+	return cfg
+}
+
+// keys returns the issue keys linked from the messages.
+func keys(messages []slacktest.Message) []string {
+	var keys []string
+	for _, m := range messages {
+		for _, link := range m.Links {
+			keys = append(keys, link.Text)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestRun(t *testing.T) {
+	jiraServer := jiratest.NewServer(t, "testdata/untriaged.json")
+	slackServer := slacktest.NewServer(t)
+	cfg := newConfig(jiraServer, slackServer)
+
+	now = func() time.Time { return time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	if err := run(context.Background(), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// One message per assignee. The bugs without an assignee, and those
+	// assigned to someone outside the team, go to the team.
+	if got := len(slackServer.Messages()); got != 4 {
+		t.Errorf("expected 4 messages, got %d", got)
+	}
+	for _, tc := range [...]struct {
+		mention  string
+		messages int
+		keys     []string
+	}{
+		{"@UALICE0001", 1, []string{"OCPBUGS-31", "OCPBUGS-32"}},
+		{"@UBOB000001", 1, []string{"OCPBUGS-33"}},
+		{cfg.Slack.TeamID, 2, []string{"OCPBUGS-34", "OCPBUGS-35"}},
+	} {
+		messages := slackServer.MessagesTo(tc.mention)
+		if len(messages) != tc.messages {
+			t.Errorf("%s: expected %d messages, got %d", tc.mention, tc.messages, len(messages))
+		}
+		if got := keys(messages); !slices.Equal(got, tc.keys) {
+			t.Errorf("%s: expected links to %q, got %q", tc.mention, tc.keys, got)
+		}
+		for _, m := range messages {
+			for _, link := range m.Links {
+				if want := cfg.IssueURL(link.Text); link.URL != want {
+					t.Errorf("%s: expected the URL %q, got %q", tc.mention, want, link.URL)
+				}
+			}
+		}
+	}
+
+	// The blocks detail each bug, with the summary escaped.
+	messages := slackServer.MessagesTo("@UALICE0001")
+	if len(messages) != 1 {
+		t.Fatalf("expected one message to alice, got %d", len(messages))
+	}
+	texts := messages[0].BlockTexts()
+	for _, want := range []string{
+		"<@UALICE0001> please triage these bugs:",
+		"*<" + cfg.IssueURL("OCPBUGS-31") + "|OCPBUGS-31>* Ports &lt;leak&gt; &amp; stay",
+		"*Priority*\nMajor",
+		"*Component*\nNetworking / kuryr",
+		"*Status*\nNew",
+		"*Reporter*\nCarol",
+		"*Age*\n3 days",
+		"*<" + cfg.IssueURL("OCPBUGS-32") + "|OCPBUGS-32>*",
+	} {
+		if !slices.Contains(texts, want) {
+			t.Errorf("expected the blocks to contain %q, got %q", want, texts)
+		}
+	}
+}
+
+func TestRunSlackFailure(t *testing.T) {
+	jiraServer := jiratest.NewServer(t, "testdata/untriaged.json")
+	slackServer := slacktest.NewServer(t)
+	slackServer.FailNext(http.StatusServiceUnavailable, 1)
+	cfg := newConfig(jiraServer, slackServer)
+
+	if err := run(context.Background(), cfg); err != cli.ErrFailed {
+		t.Fatalf("expected %v, got %v", cli.ErrFailed, err)
+	}
+	if got := len(slackServer.Messages()); got != 3 {
+		t.Errorf("expected the other 3 messages to be sent, got %d", got)
+	}
+}
+
+func TestRunWebAPI(t *testing.T) {
+	jiraServer := jiratest.NewServer(t, "testdata/untriaged.json")
+	slackServer := slacktest.NewServer(t)
+	cfg := newConfig(jiraServer, slackServer)
+	cfg.SlackHook = ""
+	cfg.SlackToken = slacktest.Token
+	cfg.Slack.APIURL = slackServer.APIURL()
+	cfg.Slack.Channel = "C0TEAM0001"
+	cfg.Slack.DirectMessages = true
+	cfg.Slack.Interactive = true
+
+	if err := run(context.Background(), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The team members get a direct message; the other reminders go to the
+	// team channel.
+	for _, tc := range [...]struct {
+		channel string
+		keys    []string
+	}{
+		{"DUALICE0001", []string{"OCPBUGS-31", "OCPBUGS-32"}},
+		{"DUBOB000001", []string{"OCPBUGS-33"}},
+		{"C0TEAM0001", []string{"OCPBUGS-34", "OCPBUGS-35"}},
+	} {
+		if got := keys(slackServer.MessagesIn(tc.channel)); !slices.Equal(got, tc.keys) {
+			t.Errorf("%s: expected links to %q, got %q", tc.channel, tc.keys, got)
+		}
+	}
+	if got := len(slackServer.MessagesIn("")); got != 0 {
+		t.Errorf("expected no message on the webhook, got %d", got)
+	}
+
+	// Each bug gets its buttons.
+	var actions []string
+	for _, m := range slackServer.MessagesIn("DUALICE0001") {
+		for _, block := range m.Blocks {
+			if block["type"] == "actions" {
+				actions = append(actions, block["block_id"].(string))
+			}
+		}
+	}
+	slices.Sort(actions)
+	if want := []string{"bugwatcher_bug:OCPBUGS-31", "bugwatcher_bug:OCPBUGS-32"}; !slices.Equal(actions, want) {
+		t.Errorf("expected the actions blocks %q, got %q", want, actions)
+	}
+}
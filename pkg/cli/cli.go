@@ -0,0 +1,211 @@
+// Package cli runs the bugwatcher subcommands with consistent flag parsing,
+// configuration loading and exit codes.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/shiftstack/bugwatcher/pkg/config"
+	"github.com/shiftstack/bugwatcher/pkg/issuecache"
+	"github.com/shiftstack/bugwatcher/pkg/jiraclient"
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+)
+
+// Exit codes, after sysexits.h
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 64
+	ExitConfig  = 78
+)
+
+// ExitError carries the exit code of a failed subcommand.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// UsageError wraps err so that the program exits with ExitUsage.
+func UsageError(err error) error { return &ExitError{Code: ExitUsage, Err: err} }
+
+// ConfigError wraps err so that the program exits with ExitConfig.
+func ConfigError(err error) error { return &ExitError{Code: ExitConfig, Err: err} }
+
+// ErrFailed signals that the subcommand ran to completion, but that some of
+// its operations failed. The failures are expected to have been logged
+// already.
+var ErrFailed = errors.New("some operations failed")
+
+// Command is a bugwatcher subcommand.
+type Command struct {
+	Name        string
+	Description string
+
+	// Env lists the environment variables that must be set.
+	Env []string
+
+	// Flags registers the flags of the subcommand. Can be nil.
+	Flags func(*flag.FlagSet)
+
+	Run func(ctx context.Context, cfg *config.Config) error
+}
+
+// Main parses the command line, runs the selected subcommand and returns the
+// exit code.
+func Main(ctx context.Context, name string, args []string, commands []Command) int {
+	global := flag.NewFlagSet(name, flag.ContinueOnError)
+	dryRun := global.Bool("dry-run", false, "print the changes to Jira and Slack instead of applying them (or set DRY_RUN=true)")
+	configFile := global.String("config", os.Getenv(config.EnvConfigFile), "path to the configuration file (or set "+config.EnvConfigFile+")")
+	jiraRecord := global.String("jira-record", "", "record the Jira requests and responses to this cassette file, with credentials and emails redacted")
+	jiraReplay := global.String("jira-replay", "", "serve the Jira responses from this cassette file instead of contacting Jira; implies -dry-run")
+	cacheFile := global.String("cache", os.Getenv(config.EnvCacheFile), "path to the issue cache file, to only download the issues updated since the previous run (or set "+config.EnvCacheFile+")")
+	global.Usage = func() { usage(global, commands) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	if global.NArg() < 1 {
+		usage(global, commands)
+		return ExitUsage
+	}
+
+	var cmd *Command
+	for i := range commands {
+		if commands[i].Name == global.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		log.Printf("Unknown subcommand %q", global.Arg(0))
+		usage(global, commands)
+		return ExitUsage
+	}
+
+	fs := flag.NewFlagSet(name+" "+cmd.Name, flag.ContinueOnError)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	if err := fs.Parse(global.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if fs.NArg() > 0 {
+		log.Printf("Unexpected arguments: %v", fs.Args())
+		return ExitUsage
+	}
+
+	if *jiraRecord != "" && *jiraReplay != "" {
+		log.Print("-jira-record and -jira-replay are mutually exclusive")
+		return ExitUsage
+	}
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		log.Print(err)
+		log.Print("Exiting.")
+		return ExitUsage
+	}
+	cfg.JiraReplay = *jiraReplay
+	if err := cfg.Require(cmd.Env...); err != nil {
+		log.Print(err)
+		log.Print("Exiting.")
+		return ExitUsage
+	}
+	if *configFile != "" {
+		cfg.File, err = config.LoadFile(*configFile)
+		if err != nil {
+			log.Print(err)
+			log.Print("Exiting.")
+			return ExitConfig
+		}
+	}
+	// A replay reproduces a past run: it must not notify Slack, which the
+	// cassette does not cover, nor change Jira.
+	if *jiraReplay != "" && !cfg.DryRun && !*dryRun {
+		log.Print("-jira-replay implies -dry-run")
+	}
+	cfg.DryRun = cfg.DryRun || *dryRun || *jiraReplay != ""
+	mutation.SetDryRun(cfg.DryRun)
+
+	if *jiraRecord != "" {
+		cfg.JiraRecorder, err = jiraclient.NewRecorder(*jiraRecord)
+		if err != nil {
+			log.Print(err)
+			log.Print("Exiting.")
+			return ExitConfig
+		}
+	}
+
+	if *cacheFile != "" {
+		cfg.IssueCache, err = issuecache.Open(*cacheFile, cfg.Jira.BaseURL, cfg.Scope().JQL())
+		if err != nil {
+			log.Print(err)
+			log.Print("Exiting.")
+			return ExitConfig
+		}
+		query.SetCache(cfg.IssueCache)
+		defer query.SetCache(nil)
+	}
+
+	code := exitCode(cmd.Run(ctx, cfg))
+
+	if cfg.JiraRecorder != nil {
+		if err := cfg.JiraRecorder.Save(); err != nil {
+			log.Print(err)
+			code = max(code, ExitFailure)
+		}
+	}
+	if cfg.IssueCache != nil {
+		if err := cfg.IssueCache.Save(); err != nil {
+			log.Print(err)
+			code = max(code, ExitFailure)
+		}
+	}
+	return code
+}
+
+// exitCode logs the error, if any, and returns the corresponding exit code.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		log.Print(exitErr.Err)
+		return exitErr.Code
+	}
+
+	if !errors.Is(err, ErrFailed) {
+		log.Print(err)
+	}
+	return ExitFailure
+}
+
+func usage(global *flag.FlagSet, commands []Command) {
+	out := global.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <subcommand> [subcommand flags]\n\nSubcommands:\n", global.Name())
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.Name, cmd.Description)
+	}
+	w.Flush()
+	fmt.Fprint(out, "\nFlags:\n")
+	global.PrintDefaults()
+	fmt.Fprintf(out, "\nRun '%s <subcommand> -h' for the flags of a subcommand.\n", global.Name())
+}
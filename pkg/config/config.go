@@ -0,0 +1,176 @@
+// Package config reads the configuration shared by all the bugwatcher
+// subcommands from the environment.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/issuecache"
+	"github.com/shiftstack/bugwatcher/pkg/jiraclient"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/team"
+)
+
+// Environment variables
+const (
+	EnvSlackHook     = "SLACK_HOOK"
+	EnvSlackToken    = "SLACK_TOKEN"
+	EnvSlackSecret   = "SLACK_SIGNING_SECRET"
+	EnvJiraEmail     = "JIRA_EMAIL"
+	EnvJiraToken     = "JIRA_TOKEN"
+	EnvJiraAccountID = "JIRA_ACCOUNT_ID"
+	EnvPeople        = "PEOPLE"
+	EnvPTOCalendar   = "PTO_CALENDAR"
+	EnvDryRun        = "DRY_RUN"
+)
+
+// Config holds the values of the environment variables and the content of
+// the configuration file.
+type Config struct {
+	File
+
+	SlackHook     string
+	SlackToken    string
+	SlackSecret   string
+	JiraEmail     string
+	JiraToken     string
+	JiraAccountID string
+	People        string
+	PTOCalendar   string
+	DryRun        bool
+
+	// JiraRecorder records the interactions with Jira, if enabled.
+	JiraRecorder *jiraclient.Recorder
+	// JiraReplay is the path of the cassette file replacing Jira, if any.
+	// JIRA_EMAIL and JIRA_TOKEN are not needed then.
+	JiraReplay string
+
+	// IssueCache is the local copy of the issues in scope, if enabled.
+	IssueCache *issuecache.Cache
+}
+
+// FromEnv reads the configuration from the environment.
+func FromEnv() (*Config, error) {
+	c := &Config{
+		File:          DefaultFile(),
+		SlackHook:     os.Getenv(EnvSlackHook),
+		SlackToken:    os.Getenv(EnvSlackToken),
+		SlackSecret:   os.Getenv(EnvSlackSecret),
+		JiraEmail:     os.Getenv(EnvJiraEmail),
+		JiraToken:     os.Getenv(EnvJiraToken),
+		JiraAccountID: os.Getenv(EnvJiraAccountID),
+		People:        os.Getenv(EnvPeople),
+		PTOCalendar:   os.Getenv(EnvPTOCalendar),
+	}
+
+	if dryRun := os.Getenv(EnvDryRun); dryRun != "" {
+		var err error
+		c.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %q", EnvDryRun, dryRun)
+		}
+	}
+
+	return c, nil
+}
+
+// value returns the value of the given environment variable.
+func (c *Config) value(name string) string {
+	switch name {
+	case EnvSlackHook:
+		return c.SlackHook
+	case EnvSlackToken:
+		return c.SlackToken
+	case EnvSlackSecret:
+		return c.SlackSecret
+	case EnvJiraEmail:
+		return c.JiraEmail
+	case EnvJiraToken:
+		return c.JiraToken
+	case EnvJiraAccountID:
+		return c.JiraAccountID
+	case EnvPeople:
+		return c.People
+	case EnvPTOCalendar:
+		return c.PTOCalendar
+	}
+	return ""
+}
+
+// Require returns an error listing the given environment variables that are
+// not set.
+func (c *Config) Require(names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if c.JiraReplay != "" && (name == EnvJiraEmail || name == EnvJiraToken) {
+			continue
+		}
+		if c.value(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required environment variables not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// JiraClient returns a Jira client authenticated with JIRA_EMAIL and
+// JIRA_TOKEN, rate-limited, and recording or replaying its interactions if
+// configured to.
+func (c *Config) JiraClient() (*jira.Client, error) {
+	var limiter *jiraclient.Limiter
+	if c.Jira.RateLimit > 0 {
+		limiter = jiraclient.NewLimiter(c.Jira.RateLimit, c.Jira.Burst)
+	}
+	opts := []jiraclient.Option{jiraclient.WithRateLimit(limiter)}
+	if c.JiraRecorder != nil {
+		opts = append(opts, jiraclient.RecordTo(c.JiraRecorder))
+	}
+	if c.JiraReplay != "" {
+		opts = append(opts, jiraclient.ReplayFrom(c.JiraReplay))
+	}
+
+	jiraClient, err := jiraclient.NewWithToken(c.Jira.BaseURL, c.JiraEmail, c.JiraToken, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error building a Jira client: %w", err)
+	}
+	return jiraClient, nil
+}
+
+// Notifier returns the Slack notifier of the subcommands. It uses the Web API
+// when SLACK_TOKEN is set, and SLACK_HOOK for the notifications without a
+// channel; one of them is required.
+func (c *Config) Notifier() (*slack.Notifier, error) {
+	if c.SlackHook == "" && (c.SlackToken == "" || c.Slack.Channel == "") {
+		return nil, fmt.Errorf("required environment variables not found: %s, or %s with slack.channel set in the configuration file", EnvSlackHook, EnvSlackToken)
+	}
+
+	opts := []slack.NotifierOption{slack.WithWebhook(c.SlackHook)}
+	if c.SlackToken != "" {
+		opts = append(opts,
+			slack.WithAPI(slack.NewAPI(c.Slack.APIURL, c.SlackToken), c.Slack.Channel),
+			slack.WithComponentChannels(c.Slack.ComponentChannels),
+			slack.WithDirectMessages(c.Slack.DirectMessages),
+		)
+	}
+	return slack.NewNotifier(opts...), nil
+}
+
+// Team loads the people from PEOPLE, with the absences from PTO_CALENDAR if
+// set.
+func (c *Config) Team(opts ...team.LoadOption) ([]team.Person, error) {
+	if c.PTOCalendar != "" {
+		opts = append(opts, team.WithTeamCalendar(c.PTOCalendar))
+	}
+
+	people, err := team.Load(strings.NewReader(c.People), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching team information: %w", err)
+	}
+	return people, nil
+}
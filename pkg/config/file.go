@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/shiftstack/bugwatcher/pkg/jiraclient"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"github.com/shiftstack/bugwatcher/pkg/rules"
+	"github.com/shiftstack/bugwatcher/pkg/slack"
+	"github.com/shiftstack/bugwatcher/pkg/workpool"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigFile is the environment variable holding the path to the
+// configuration file.
+const EnvConfigFile = "BUGWATCHER_CONFIG"
+
+// EnvCacheFile is the environment variable holding the path to the issue
+// cache file.
+const EnvCacheFile = "BUGWATCHER_CACHE"
+
+// FileVersion is the version of the configuration file format understood by
+// this program.
+const FileVersion = 1
+
+// File is the content of the configuration file.
+type File struct {
+	Version int `yaml:"version"`
+
+	Jira struct {
+		// BaseURL is the address of the Jira instance.
+		BaseURL string `yaml:"base_url"`
+		// Project is the name of the Jira project holding the bugs.
+		Project string `yaml:"project"`
+		// RateLimit is the maximum average number of requests per second
+		// sent to Jira, shared by all the goroutines of a subcommand. Zero
+		// disables the limit.
+		RateLimit float64 `yaml:"rate_limit"`
+		// Burst is the number of requests that can be sent at once before
+		// being slowed down by RateLimit.
+		Burst int `yaml:"burst"`
+	} `yaml:"jira"`
+
+	// Components are the Jira components owned by the team.
+	Components []string `yaml:"components"`
+
+	// ExcludedLabels are the labels of the bugs to ignore.
+	ExcludedLabels []string `yaml:"excluded_labels"`
+
+	Slack struct {
+		// TeamID is the mention of the Slack user group of the team, used
+		// when a bug has no known assignee. See
+		// https://api.slack.com/reference/surfaces/formatting#mentioning-groups
+		TeamID string `yaml:"team_id"`
+		// APIURL is the base URL of the Slack Web API, used when
+		// SLACK_TOKEN is set.
+		APIURL string `yaml:"api_url"`
+		// Channel is the ID of the channel receiving the notifications
+		// with the Web API. When empty, they are posted to SLACK_HOOK.
+		Channel string `yaml:"channel"`
+		// ComponentChannels are the IDs of the channels receiving the
+		// notifications about the bugs of a component, by component.
+		ComponentChannels map[string]string `yaml:"component_channels"`
+		// DirectMessages sends the notifications addressed to a person
+		// in a direct message.
+		DirectMessages bool `yaml:"direct_messages"`
+		// Interactive adds buttons to the bugs of the triage
+		// notifications, handled by the serve subcommand.
+		Interactive bool `yaml:"interactive"`
+		// HandoffComponents are the components offered when a bug is not
+		// for the team, by the interactive notifications.
+		HandoffComponents []string `yaml:"handoff_components"`
+	} `yaml:"slack"`
+
+	// Concurrency is the number of issues processed at once by the
+	// subcommands.
+	Concurrency int `yaml:"concurrency"`
+
+	// Rules are the triage requirements enforced on top of the built-in
+	// checks of the commands.
+	Rules rules.Rules `yaml:"rules"`
+}
+
+// DefaultFile returns the configuration of the ShiftStack team.
+func DefaultFile() File {
+	var f File
+	f.Version = FileVersion
+	f.Jira.BaseURL = "https://redhat.atlassian.net/"
+	f.Jira.Project = "OpenShift Bugs"
+	f.Jira.RateLimit = jiraclient.DefaultRate
+	f.Jira.Burst = jiraclient.DefaultBurst
+	f.Components = []string{
+		"Installer / OpenShift on OpenStack",
+		"Storage / OpenStack CSI Drivers",
+		"Cloud Compute / OpenStack Provider",
+		"Machine Config Operator / platform-openstack",
+		"Networking / kuryr",
+		"Test Framework / OpenStack",
+		"HyperShift / OpenStack",
+	}
+	f.ExcludedLabels = []string{
+		"bugwatcher-ignore",
+		"SecurityTracking",
+	}
+	// ID of @ocp-openstack-team
+	f.Slack.TeamID = "!subteam^SKW6QC31Q"
+	f.Slack.APIURL = slack.DefaultAPIURL
+	f.Concurrency = workpool.DefaultConcurrency
+	return f
+}
+
+// LoadFile reads and validates the configuration file at path. The values
+// that are not set in the file take their default value, except for the
+// version which is mandatory.
+func LoadFile(path string) (File, error) {
+	f := DefaultFile()
+	f.Version = 0
+
+	r, err := os.Open(path)
+	if err != nil {
+		return f, fmt.Errorf("error opening the configuration file: %w", err)
+	}
+	defer r.Close()
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&f); err != nil {
+		return f, fmt.Errorf("error decoding the configuration file %q: %w", path, err)
+	}
+
+	if err := f.Validate(); err != nil {
+		return f, fmt.Errorf("invalid configuration file %q: %w", path, err)
+	}
+	return f, nil
+}
+
+// maxHandoffComponents is the maximum number of options of a Slack select
+// menu.
+const maxHandoffComponents = 100
+
+var (
+	slackGroupPattern   = regexp.MustCompile(`^!subteam\^[A-Z0-9]+$`)
+	slackChannelPattern = regexp.MustCompile(`^[CG][A-Z0-9]+$`)
+)
+
+// Validate returns an error describing the first problem found in the
+// configuration.
+func (f File) Validate() error {
+	if f.Version != FileVersion {
+		return fmt.Errorf("unsupported version %d: expected %d", f.Version, FileVersion)
+	}
+
+	u, err := url.Parse(f.Jira.BaseURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("jira.base_url: %q is not an HTTP(S) URL", f.Jira.BaseURL)
+	}
+
+	if f.Jira.Project == "" {
+		return fmt.Errorf("jira.project must not be empty")
+	}
+
+	if f.Jira.RateLimit < 0 {
+		return fmt.Errorf("jira.rate_limit must not be negative")
+	}
+	if f.Jira.Burst < 1 {
+		return fmt.Errorf("jira.burst must be at least 1")
+	}
+
+	if len(f.Components) == 0 {
+		return fmt.Errorf("components must not be empty")
+	}
+	seen := make(map[string]struct{}, len(f.Components))
+	for _, component := range f.Components {
+		if strings.TrimSpace(component) == "" {
+			return fmt.Errorf("components: empty component name")
+		}
+		if _, ok := seen[component]; ok {
+			return fmt.Errorf("components: duplicate component %q", component)
+		}
+		seen[component] = struct{}{}
+	}
+
+	for _, label := range f.ExcludedLabels {
+		if strings.TrimSpace(label) == "" || strings.ContainsAny(label, " \t") {
+			return fmt.Errorf("excluded_labels: %q is not a valid label", label)
+		}
+	}
+
+	if !slackGroupPattern.MatchString(f.Slack.TeamID) {
+		return fmt.Errorf("slack.team_id: %q is not a Slack user group mention, e.g. !subteam^SKW6QC31Q", f.Slack.TeamID)
+	}
+
+	u, err = url.Parse(f.Slack.APIURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("slack.api_url: %q is not an HTTP(S) URL", f.Slack.APIURL)
+	}
+	if f.Slack.Channel != "" && !slackChannelPattern.MatchString(f.Slack.Channel) {
+		return fmt.Errorf("slack.channel: %q is not a Slack channel ID, e.g. C012AB3CD", f.Slack.Channel)
+	}
+	for component, channel := range f.Slack.ComponentChannels {
+		if _, ok := seen[component]; !ok {
+			return fmt.Errorf("slack.component_channels: %q is not one of the components", component)
+		}
+		if !slackChannelPattern.MatchString(channel) {
+			return fmt.Errorf("slack.component_channels: %q is not a Slack channel ID, e.g. C012AB3CD", channel)
+		}
+	}
+
+	if len(f.Slack.HandoffComponents) > maxHandoffComponents {
+		return fmt.Errorf("slack.handoff_components: at most %d components can be offered", maxHandoffComponents)
+	}
+	for _, component := range f.Slack.HandoffComponents {
+		if strings.TrimSpace(component) == "" {
+			return fmt.Errorf("slack.handoff_components: empty component name")
+		}
+		if _, ok := seen[component]; ok {
+			return fmt.Errorf("slack.handoff_components: %q is one of the components", component)
+		}
+	}
+
+	if f.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+
+	if err := f.Rules.Validate(); err != nil {
+		return fmt.Errorf("rules: %w", err)
+	}
+
+	return nil
+}
+
+// Scope returns the set of bugs defined by the configuration.
+func (f File) Scope() query.Scope {
+	return query.Scope{
+		Project:        f.Jira.Project,
+		Components:     f.Components,
+		ExcludedLabels: f.ExcludedLabels,
+	}
+}
+
+// IssueURL returns the URL of the web page of the issue.
+func (f File) IssueURL(key string) string {
+	return query.IssueURL(f.Jira.BaseURL, key)
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const shiftStackJQL = `project = "OpenShift Bugs"
+	AND (
+		component in (
+			"Installer / OpenShift on OpenStack",
+			"Storage / OpenStack CSI Drivers",
+			"Cloud Compute / OpenStack Provider",
+			"Machine Config Operator / platform-openstack",
+			"Networking / kuryr",
+			"Test Framework / OpenStack",
+			"HyperShift / OpenStack"
+		)
+	)
+	AND labels != "bugwatcher-ignore"
+	AND labels != "SecurityTracking"
+`
+
+func TestDefaultFile(t *testing.T) {
+	f := DefaultFile()
+	if err := f.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.Scope().JQL(); got != shiftStackJQL {
+		t.Errorf("unexpected query:\n%s", got)
+	}
+	if got, want := f.IssueURL("OCPBUGS-1"), "https://redhat.atlassian.net/browse/OCPBUGS-1"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	f, err := LoadFile("testdata/valid.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Components) != 1 {
+		t.Errorf("expected the components to be replaced, got %q", f.Components)
+	}
+	if len(f.Rules) != 1 || len(f.Rules.Fields()) != 1 {
+		t.Errorf("expected one rule reading one custom field, got %v", f.Rules)
+	}
+	if f.Jira.BaseURL != DefaultFile().Jira.BaseURL {
+		t.Errorf("expected the default base URL, got %q", f.Jira.BaseURL)
+	}
+
+	for _, tc := range [...]struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown version", "version: 2", "unsupported version"},
+		{"missing version", "jira:\n  project: X", "unsupported version"},
+		{"unknown key", "version: 1\nslack_hook: x", "field slack_hook not found"},
+		{"bad base URL", "version: 1\njira:\n  base_url: redhat.atlassian.net", "jira.base_url"},
+		{"negative rate limit", "version: 1\njira:\n  rate_limit: -1", "jira.rate_limit"},
+		{"no burst", "version: 1\njira:\n  burst: 0", "jira.burst"},
+		{"no components", "version: 1\ncomponents: []", "components must not be empty"},
+		{"duplicate component", "version: 1\ncomponents: [a, a]", "duplicate component"},
+		{"bad label", "version: 1\nexcluded_labels: [\"a b\"]", "excluded_labels"},
+		{"bad rule", "version: 1\nrules:\n  - {name: a, expr: 'prio = 1', message: m, scope: [posttriage]}", "unknown field"},
+		{"unknown rule key", "version: 1\nrules:\n  - {name: a, expr: 'priority is empty', message: m, severty: warning, scope: [posttriage]}", "field severty not found"},
+		{"no concurrency", "version: 1\nconcurrency: 0", "concurrency must be at least 1"},
+		{"bad team ID", "version: 1\nslack:\n  team_id: \"@team\"", "slack.team_id"},
+		{"bad Slack API URL", "version: 1\nslack:\n  api_url: slack.com/api", "slack.api_url"},
+		{"bad channel", "version: 1\nslack:\n  channel: \"#bugs\"", "slack.channel"},
+		{"unknown channel component", "version: 1\nslack:\n  component_channels:\n    Storage: C012AB3CD", "not one of the components"},
+		{"bad component channel", "version: 1\nslack:\n  component_channels:\n    Networking / kuryr: kuryr", "slack.component_channels"},
+		{"own handoff component", "version: 1\nslack:\n  handoff_components:\n    - Networking / kuryr", "is one of the components"},
+		{"empty handoff component", "version: 1\nslack:\n  handoff_components:\n    - \"\"", "slack.handoff_components"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bugwatcher.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadFile(path)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Package fields resolves the IDs of the Jira custom fields from their display
+// names.
+//
+// The IDs differ between Jira instances and can change when an administrator
+// recreates a field; resolving them at startup makes such a change fail
+// loudly instead of silently breaking the checks.
+//
+// The options of the select fields are matched on their values, which are
+// checked to exist the same way.
+package fields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// Names of the custom fields used by bugwatcher.
+const (
+	ReleaseNoteType = "Release Note Type"
+	ReleaseNoteText = "Release Note Text"
+	ReleaseBlocker  = "Release Blocker"
+	TestCoverage    = "Test Coverage"
+	CVE             = "CVE ID"
+	NeedInfoFrom    = "Need Info From"
+	TargetVersion   = "Target Version"
+	Severity        = "Severity"
+)
+
+// Values of the options of the select fields used by bugwatcher.
+const (
+	ReleaseNoteNotRequired = "Release Note Not Required"
+
+	ReleaseBlockerApproved = "Approved"
+	ReleaseBlockerProposed = "Proposed"
+	ReleaseBlockerRejected = "Rejected"
+
+	TestCoverageAutomated = "+"
+	TestCoverageManual    = "-"
+	TestCoverageNone      = "?"
+)
+
+// requiredOptions lists, for the select fields, the options that the checks
+// match on their values.
+var requiredOptions = map[string][]string{
+	ReleaseNoteType: {ReleaseNoteNotRequired},
+	ReleaseBlocker:  {ReleaseBlockerApproved, ReleaseBlockerProposed, ReleaseBlockerRejected},
+	TestCoverage:    {TestCoverageAutomated, TestCoverageManual, TestCoverageNone},
+}
+
+// ErrNotFound is returned when a field or an option does not exist in Jira.
+var ErrNotFound = errors.New("not found")
+
+// Registry maps the names of the custom fields to their IDs.
+type Registry struct {
+	ids map[string]string
+}
+
+// New returns a registry holding the given field IDs by name, e.g. for tests.
+func New(ids map[string]string) *Registry {
+	return &Registry{ids: ids}
+}
+
+// Load fetches the IDs of the named fields from the Jira instance of client,
+// and checks that the select fields have the options bugwatcher relies on. It
+// returns an error wrapping ErrNotFound if any of them does not exist, and an
+// error if the options cannot be read, e.g. for lack of permissions.
+func Load(ctx context.Context, client *jira.Client, names ...string) (*Registry, error) {
+	list, _, err := client.Field.GetListWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching the Jira fields: %w", err)
+	}
+
+	r := &Registry{ids: make(map[string]string, len(names))}
+	var missing []string
+	for _, name := range names {
+		id, err := fieldID(list, name)
+		if err != nil {
+			missing = append(missing, err.Error())
+			continue
+		}
+		r.ids[name] = id
+
+		if required, ok := requiredOptions[name]; ok {
+			options, err := fieldOptions(ctx, client, id)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching the options of the field %q: %w", name, err)
+			}
+			for _, value := range required {
+				if !options[value] {
+					missing = append(missing, fmt.Sprintf("option %q of the field %q", value, name))
+				}
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("Jira custom fields %w: %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+	return r, nil
+}
+
+// ID returns the ID of the named field, e.g. "customfield_10785". It panics if
+// the field was not passed to Load.
+func (r *Registry) ID(name string) string {
+	id, ok := r.ids[name]
+	if !ok {
+		panic(fmt.Sprintf("field %q was not loaded", name))
+	}
+	return id
+}
+
+// IDs returns the IDs of the named fields, in the same order. It panics if a
+// field was not passed to Load.
+func (r *Registry) IDs(names ...string) []string {
+	ids := make([]string, len(names))
+	for i, name := range names {
+		ids[i] = r.ID(name)
+	}
+	return ids
+}
+
+// fieldID returns the ID of the custom field with the given name.
+func fieldID(list []jira.Field, name string) (string, error) {
+	var ids []string
+	for _, field := range list {
+		if field.Custom && field.Name == name {
+			ids = append(ids, field.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("field %q", name)
+	case 1:
+		return ids[0], nil
+	default:
+		sort.Strings(ids)
+		return "", fmt.Errorf("field %q is ambiguous (%s)", name, strings.Join(ids, ", "))
+	}
+}
+
+// fieldOptions returns the values of the options of the field, across all its
+// contexts.
+func fieldOptions(ctx context.Context, client *jira.Client, fieldID string) (map[string]bool, error) {
+	type fieldContext struct {
+		ID string `json:"id"`
+	}
+	contexts, err := getAll[fieldContext](ctx, client, "rest/api/2/field/"+url.PathEscape(fieldID)+"/context")
+	if err != nil {
+		return nil, err
+	}
+
+	type option struct {
+		Value string `json:"value"`
+	}
+	options := make(map[string]bool)
+	for _, c := range contexts {
+		values, err := getAll[option](ctx, client, "rest/api/2/field/"+url.PathEscape(fieldID)+"/context/"+url.PathEscape(c.ID)+"/option")
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range values {
+			options[o.Value] = true
+		}
+	}
+	return options, nil
+}
+
+// getAll fetches all the pages of a paginated Jira endpoint.
+func getAll[T any](ctx context.Context, client *jira.Client, endpoint string) ([]T, error) {
+	var all []T
+	for {
+		req, err := client.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s?startAt=%d", endpoint, len(all)), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Values []T  `json:"values"`
+			IsLast bool `json:"isLast"`
+		}
+		resp, err := client.Do(req, &page)
+		if err != nil {
+			return nil, jira.NewJiraError(resp, err)
+		}
+
+		all = append(all, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			return all, nil
+		}
+	}
+}
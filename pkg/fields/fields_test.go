@@ -0,0 +1,119 @@
+package fields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+const fieldList = `[
+	{"id": "summary", "name": "Summary", "custom": false},
+	{"id": "customfield_10785", "name": "Release Note Type", "custom": true},
+	{"id": "customfield_10783", "name": "Release Note Text", "custom": true},
+	{"id": "customfield_10847", "name": "Release Blocker", "custom": true},
+	{"id": "customfield_10638", "name": "Test Coverage", "custom": true},
+	{"id": "customfield_10900", "name": "Duplicated", "custom": true},
+	{"id": "customfield_10901", "name": "Duplicated", "custom": true}
+]`
+
+// newServer returns a Jira server with a single context per field, whose
+// option pages hold a single option each.
+func newServer(t *testing.T) *jira.Client {
+	t.Helper()
+
+	options := map[string][]string{
+		"customfield_10785": {`{"id": "12510", "value": "Release Note Not Required"}`, `{"id": "12511", "value": "Bug Fix"}`},
+		"customfield_10847": {`{"id": "16772", "value": "Approved"}`, `{"id": "16773", "value": "Proposed"}`},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/2/field", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, fieldList)
+	})
+	mux.HandleFunc("/rest/api/2/field/{id}/context", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "customfield_10638" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errorMessages": ["You are not allowed to access this field."]}`)
+			return
+		}
+		fmt.Fprint(w, `{"values": [{"id": "1"}], "isLast": true}`)
+	})
+	mux.HandleFunc("/rest/api/2/field/{id}/context/1/option", func(w http.ResponseWriter, r *http.Request) {
+		values := options[r.PathValue("id")]
+		var startAt int
+		fmt.Sscan(r.URL.Query().Get("startAt"), &startAt)
+		if startAt >= len(values) {
+			fmt.Fprint(w, `{"values": [], "isLast": true}`)
+			return
+		}
+		fmt.Fprintf(w, `{"values": [%s], "isLast": %t}`, values[startAt], startAt == len(values)-1)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := jira.NewClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestLoad(t *testing.T) {
+	client := newServer(t)
+
+	r, err := Load(context.Background(), client, ReleaseNoteType, ReleaseNoteText)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := r.ID(ReleaseNoteType); got != "customfield_10785" {
+		t.Errorf("unexpected ID for %q: %q", ReleaseNoteType, got)
+	}
+	if got := r.ID(ReleaseNoteText); got != "customfield_10783" {
+		t.Errorf("unexpected ID for %q: %q", ReleaseNoteText, got)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	client := newServer(t)
+
+	for _, tc := range [...]struct {
+		name  string
+		field string
+		want  string
+	}{
+		{"missing field", CVE, `field "CVE ID"`},
+		{"missing option", ReleaseBlocker, `option "Rejected" of the field "Release Blocker"`},
+		{"ambiguous field", "Duplicated", "customfield_10900, customfield_10901"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(context.Background(), client, tc.field)
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadForbiddenOptions(t *testing.T) {
+	client := newServer(t)
+
+	_, err := Load(context.Background(), client, TestCoverage)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the options not to be reported missing, got %v", err)
+	}
+	if want := `options of the field "Test Coverage"`; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected an error containing %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,276 @@
+// Package ical reads the events of an iCalendar (RFC 5545) feed.
+//
+// Only what is needed to represent absences is supported. Recurrence rules are
+// kept on the event and expanded by Occurrences, which supports the common
+// daily, weekly, monthly and yearly rules.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is a VEVENT.
+type Event struct {
+	UID     string
+	Summary string
+	Status  string
+
+	// Start is inclusive; End is exclusive.
+	Start time.Time
+	End   time.Time
+
+	// AllDay is true when the event is expressed in dates rather than
+	// date-times. In that case, Start and End are midnight in the location
+	// passed to Parse.
+	AllDay bool
+
+	// Organizer and Attendees hold email addresses, stripped of the
+	// "mailto:" prefix.
+	Organizer string
+	Attendees []string
+
+	// Recurrence is the value of the RRULE property, e.g.
+	// "FREQ=WEEKLY;BYDAY=FR;COUNT=4". It is empty for single events.
+	Recurrence string
+	// Exceptions are the start times of the occurrences excluded with
+	// EXDATE.
+	Exceptions []time.Time
+}
+
+// property is a content line, e.g. "DTSTART;TZID=Europe/Paris:20250101T090000".
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse returns the events of the calendar. Dates and floating date-times are
+// interpreted in the given location.
+func Parse(r io.Reader, location *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events []Event
+		event  *Event
+		depth  int
+
+		// DURATION may precede DTSTART, so it is applied at the end
+		durationDays int
+		duration     time.Duration
+		hasDuration  bool
+	)
+	for n, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && prop.value == "VEVENT":
+			event = new(Event)
+			depth = 0
+			durationDays, duration, hasDuration = 0, 0, false
+			continue
+		case event == nil:
+			continue
+		case prop.name == "BEGIN":
+			// Nested component, e.g. VALARM
+			depth++
+			continue
+		case prop.name == "END" && depth > 0:
+			depth--
+			continue
+		case depth > 0:
+			continue
+		case prop.name == "END" && prop.value == "VEVENT":
+			if hasDuration {
+				event.End = event.Start.AddDate(0, 0, durationDays).Add(duration)
+			}
+			if event.End.IsZero() {
+				if event.AllDay {
+					event.End = event.Start.AddDate(0, 0, 1)
+				} else {
+					event.End = event.Start
+				}
+			}
+			events = append(events, *event)
+			event = nil
+			continue
+		}
+
+		switch prop.name {
+		case "UID":
+			event.UID = prop.value
+		case "SUMMARY":
+			event.Summary = unescape(prop.value)
+		case "STATUS":
+			event.Status = strings.ToUpper(prop.value)
+		case "ORGANIZER":
+			event.Organizer = email(prop.value)
+		case "ATTENDEE":
+			event.Attendees = append(event.Attendees, email(prop.value))
+		case "DTSTART":
+			event.Start, event.AllDay, err = parseTime(prop, location)
+		case "DTEND":
+			event.End, _, err = parseTime(prop, location)
+		case "DURATION":
+			durationDays, duration, err = parseDuration(prop.value)
+			hasDuration = true
+		case "RRULE":
+			event.Recurrence = prop.value
+		case "EXDATE":
+			for _, value := range strings.Split(prop.value, ",") {
+				var exception time.Time
+				exception, _, err = parseTime(property{name: prop.name, params: prop.params, value: value}, location)
+				if err != nil {
+					break
+				}
+				event.Exceptions = append(event.Exceptions, exception)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+
+	return events, nil
+}
+
+// unfold joins the continuation lines, which start with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading the calendar: %w", err)
+	}
+	return lines, nil
+}
+
+func parseProperty(line string) (property, error) {
+	var (
+		prop     property
+		inQuotes bool
+		colon    = -1
+	)
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return prop, fmt.Errorf("invalid content line %q", line)
+	}
+
+	prop.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	prop.name = strings.ToUpper(parts[0])
+	prop.params = make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return prop, nil
+}
+
+// parseTime parses a DATE or DATE-TIME value. The returned boolean is true
+// for DATE values.
+func parseTime(prop property, location *time.Location) (time.Time, bool, error) {
+	if prop.params["VALUE"] == "DATE" || len(prop.value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", prop.value, location)
+		if err != nil {
+			return t, true, fmt.Errorf("invalid %s: %w", prop.name, err)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(prop.value, "Z") {
+		t, err := time.Parse("20060102T150405Z", prop.value)
+		if err != nil {
+			return t, false, fmt.Errorf("invalid %s: %w", prop.name, err)
+		}
+		return t, false, nil
+	}
+
+	if tzid := prop.params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s: unknown TZID %q", prop.name, tzid)
+		}
+		location = l
+	}
+	t, err := time.ParseInLocation("20060102T150405", prop.value, location)
+	if err != nil {
+		return t, false, fmt.Errorf("invalid %s: %w", prop.name, err)
+	}
+	return t, false, nil
+}
+
+// parseDuration parses the RFC 5545 duration format, e.g. "P1D" or "PT4H30M".
+// Days are returned separately, because they are calendar days.
+func parseDuration(value string) (days int, d time.Duration, err error) {
+	s, ok := strings.CutPrefix(strings.TrimPrefix(value, "+"), "P")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid DURATION %q", value)
+	}
+
+	var inTime bool
+	var n int
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			n = n*10 + int(c-'0')
+			continue
+		case c == 'T':
+			inTime = true
+		case c == 'W' && !inTime:
+			days += 7 * n
+		case c == 'D' && !inTime:
+			days += n
+		case c == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case c == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case c == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, 0, fmt.Errorf("invalid DURATION %q", value)
+		}
+		n = 0
+	}
+	return days, d, nil
+}
+
+func email(value string) string {
+	if i := strings.Index(strings.ToLower(value), "mailto:"); i >= 0 {
+		value = value[i+len("mailto:"):]
+	}
+	return strings.ToLower(value)
+}
+
+var unescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescape(value string) string {
+	return unescaper.Replace(value)
+}
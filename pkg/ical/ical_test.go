@@ -0,0 +1,148 @@
+package ical
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:pto@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20261019\r\n" +
+	"DTEND;VALUE=DATE:20261021\r\n" +
+	"SUMMARY:PTO\\, finally\r\n" +
+	"ORGANIZER;CN=\"Doe: John\":mailto:JDoe@example.com\r\n" +
+	"ATTENDEE:mailto:user1@example.com\r\n" +
+	"ATTENDEE:mailto:user2@example.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:doctor@example.com\r\n" +
+	"DTSTART;TZID=Europe/Paris:20261022T140000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"SUMMARY:Doctor\r\n" +
+	" 's appointment\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:recurring@example.com\r\n" +
+	"DTSTART:20261023T090000Z\r\n" +
+	"DTEND:20261023T100000Z\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=FR\r\n" +
+	"EXDATE:20261030T090000Z,20261106T090000Z\r\n" +
+	"STATUS:cancelled\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	events, err := Parse(strings.NewReader(calendar), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	pto := events[0]
+	if !pto.AllDay || !pto.Start.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) || !pto.End.Equal(time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected all-day event: %+v", pto)
+	}
+	if pto.Summary != "PTO, finally" {
+		t.Errorf("expected the summary to be unescaped, got %q", pto.Summary)
+	}
+	if pto.Organizer != "jdoe@example.com" || !slices.Equal(pto.Attendees, []string{"user1@example.com", "user2@example.com"}) {
+		t.Errorf("unexpected organizer %q or attendees %q", pto.Organizer, pto.Attendees)
+	}
+
+	doctor := events[1]
+	if doctor.AllDay || !doctor.Start.Equal(time.Date(2026, 10, 22, 14, 0, 0, 0, paris)) || doctor.End.Sub(doctor.Start) != 90*time.Minute {
+		t.Errorf("unexpected event with a duration: %+v", doctor)
+	}
+	if doctor.Summary != "Doctor's appointment" {
+		t.Errorf("expected the summary to be unfolded, got %q", doctor.Summary)
+	}
+
+	recurring := events[2]
+	if recurring.Status != "CANCELLED" || recurring.Recurrence != "FREQ=WEEKLY;BYDAY=FR" || len(recurring.Exceptions) != 2 {
+		t.Errorf("unexpected recurring event: %+v", recurring)
+	}
+
+	if _, err := Parse(strings.NewReader("BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"), time.UTC); err == nil {
+		t.Errorf("expected an error for an invalid DTSTART")
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	date := func(month time.Month, day int) time.Time { return time.Date(2026, month, day, 9, 0, 0, 0, time.UTC) }
+	from, until := date(8, 1), date(12, 31)
+
+	for _, tc := range [...]struct {
+		name       string
+		start      time.Time
+		rule       string
+		exceptions []time.Time
+		want       []time.Time
+	}{
+		{"single", date(10, 19), "", nil, []time.Time{date(10, 19)}},
+		{"daily", date(10, 19), "FREQ=DAILY;COUNT=3", nil, []time.Time{date(10, 19), date(10, 20), date(10, 21)}},
+		{"every other day", date(10, 19), "FREQ=DAILY;INTERVAL=2;UNTIL=20261023T090000Z", nil, []time.Time{date(10, 19), date(10, 21), date(10, 23)}},
+		{"weekly", date(10, 19), "FREQ=WEEKLY;COUNT=3", []time.Time{date(10, 26)}, []time.Time{date(10, 19), date(11, 2)}},
+		{"weekly by day", date(10, 21), "FREQ=WEEKLY;BYDAY=FR,MO;COUNT=4", nil, []time.Time{date(10, 23), date(10, 26), date(10, 30), date(11, 2)}},
+		{"every other week by day", date(10, 19), "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TU;UNTIL=20261103", nil, []time.Time{date(10, 19), date(10, 20), date(11, 2), date(11, 3)}},
+		{"monthly", date(8, 31), "FREQ=MONTHLY", nil, []time.Time{date(8, 31), date(10, 31)}},
+		{"yearly", date(10, 19), "FREQ=YEARLY;COUNT=2", nil, []time.Time{date(10, 19)}},
+		{"before the window", date(7, 20), "FREQ=WEEKLY;COUNT=3", nil, []time.Time{date(8, 3)}},
+		{"years-old daily", time.Date(2016, 1, 4, 9, 0, 0, 0, time.UTC), "FREQ=DAILY;UNTIL=20260803", nil, []time.Time{date(8, 1), date(8, 2), date(8, 3)}},
+		{"years-old weekly", time.Date(2006, 1, 2, 9, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=MO;UNTIL=20260818", nil, []time.Time{date(8, 3), date(8, 10), date(8, 17)}},
+		{"years-old count", time.Date(2016, 1, 4, 9, 0, 0, 0, time.UTC), "FREQ=WEEKLY;COUNT=553", nil, []time.Time{date(8, 3)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event := Event{Start: tc.start, End: tc.start.Add(time.Hour), Recurrence: tc.rule, Exceptions: tc.exceptions}
+			occurrences, err := event.Occurrences(from, until)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []time.Time
+			for _, o := range occurrences {
+				if o.End.Sub(o.Start) != time.Hour || o.Recurrence != "" {
+					t.Errorf("unexpected occurrence: %+v", o)
+				}
+				got = append(got, o.Start)
+			}
+			if !slices.EqualFunc(got, tc.want, time.Time.Equal) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestOccurrencesUnsupported(t *testing.T) {
+	start := time.Date(2026, 10, 28, 15, 0, 0, 0, time.UTC)
+	for _, rule := range []string{
+		"FREQ=MONTHLY;BYDAY=-1WE",
+		"FREQ=MONTHLY;BYSETPOS=-1;BYDAY=WE",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;WKST=SU",
+	} {
+		t.Run(rule, func(t *testing.T) {
+			occurrences, err := Event{Start: start, End: start.Add(time.Hour), Recurrence: rule}.Occurrences(start, start.AddDate(1, 0, 0))
+			if !errors.Is(err, ErrUnsupportedRule) {
+				t.Errorf("expected %v, got %v", ErrUnsupportedRule, err)
+			}
+			if len(occurrences) != 1 || !occurrences[0].Start.Equal(start) {
+				t.Errorf("expected the first occurrence alone, got %+v", occurrences)
+			}
+		})
+	}
+
+	if _, err := (Event{Start: start, Recurrence: "FREQ=DAILY;COUNT=many"}).Occurrences(start, start.AddDate(1, 0, 0)); err == nil || errors.Is(err, ErrUnsupportedRule) {
+		t.Errorf("expected an invalid rule error, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedRule is returned by Occurrences for the recurrence rules it
+// cannot expand.
+var ErrUnsupportedRule = errors.New("unsupported recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// rule is a parsed RRULE.
+type rule struct {
+	freq     string
+	interval int
+	count    int
+	// until is exclusive
+	until time.Time
+	byDay []time.Weekday
+}
+
+// Occurrences returns the occurrences of the event that end after from and
+// start before until, excluding those listed in EXDATE. An event without a
+// recurrence rule is its own single occurrence, whatever its dates.
+//
+// Only FREQ, INTERVAL, COUNT, UNTIL, and BYDAY with a weekly frequency are
+// supported. Other rules return the first occurrence alone, with an error
+// wrapping ErrUnsupportedRule.
+func (e Event) Occurrences(from, until time.Time) ([]Event, error) {
+	single := e
+	single.Recurrence, single.Exceptions = "", nil
+	if e.Recurrence == "" {
+		return []Event{single}, nil
+	}
+
+	r, err := parseRule(e.Recurrence, e.Start.Location())
+	if err != nil {
+		return []Event{single}, err
+	}
+
+	var (
+		occurrences []Event
+		duration    = e.End.Sub(e.Start)
+		n           int
+	)
+	// The rule is expanded from DTSTART, which COUNT counts from, up to the
+	// end of the window however old the event is.
+	for start := range r.starts(e.Start) {
+		if !start.Before(until) || (!r.until.IsZero() && !start.Before(r.until)) {
+			break
+		}
+		if r.count > 0 && n >= r.count {
+			break
+		}
+		n++
+		if !start.Add(duration).After(from) || slices.ContainsFunc(e.Exceptions, start.Equal) {
+			continue
+		}
+		occurrence := single
+		occurrence.Start, occurrence.End = start, start.Add(duration)
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// starts returns an iterator over the start times of the rule, in order.
+func (r rule) starts(first time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if r.freq == "WEEKLY" && len(r.byDay) > 0 {
+			// Weeks start on Monday
+			monday := first.AddDate(0, 0, -(int(first.Weekday())+6)%7)
+			for week := 0; ; week += r.interval {
+				for _, day := range r.byDay {
+					start := monday.AddDate(0, 0, 7*week+(int(day)+6)%7)
+					if !start.Before(first) {
+						if !yield(start) {
+							return
+						}
+					}
+				}
+			}
+		}
+
+		for i := 0; ; i++ {
+			var start time.Time
+			switch r.freq {
+			case "DAILY":
+				start = first.AddDate(0, 0, i*r.interval)
+			case "WEEKLY":
+				start = first.AddDate(0, 0, 7*i*r.interval)
+			case "MONTHLY":
+				start = first.AddDate(0, i*r.interval, 0)
+			case "YEARLY":
+				start = first.AddDate(i*r.interval, 0, 0)
+			}
+			// Dates that do not exist in a month or a year, e.g.
+			// February 30, are skipped
+			if (r.freq == "MONTHLY" || r.freq == "YEARLY") && start.Day() != first.Day() {
+				continue
+			}
+			if !yield(start) {
+				return
+			}
+		}
+	}
+}
+
+func parseRule(value string, location *time.Location) (rule, error) {
+	r := rule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(v)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("invalid INTERVAL %q", v)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+		case "UNTIL":
+			// UNTIL is inclusive, and covers the whole day if it is a
+			// date
+			var allDay bool
+			r.until, allDay, err = parseTime(property{name: "UNTIL", value: v}, location)
+			if allDay {
+				r.until = r.until.AddDate(0, 0, 1)
+			} else {
+				r.until = r.until.Add(time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(v), ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return r, fmt.Errorf("%w: BYDAY=%s", ErrUnsupportedRule, v)
+				}
+				r.byDay = append(r.byDay, weekday)
+			}
+		case "WKST":
+			if !strings.EqualFold(v, "MO") {
+				return r, fmt.Errorf("%w: WKST=%s", ErrUnsupportedRule, v)
+			}
+		default:
+			return r, fmt.Errorf("%w: %s", ErrUnsupportedRule, part)
+		}
+		if err != nil {
+			return r, fmt.Errorf("invalid RRULE %q: %w", value, err)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return r, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRule, r.freq)
+	}
+	if len(r.byDay) > 0 && r.freq != "WEEKLY" {
+		return r, fmt.Errorf("%w: BYDAY with FREQ=%s", ErrUnsupportedRule, r.freq)
+	}
+	// Weeks start on Monday
+	slices.SortFunc(r.byDay, func(a, b time.Weekday) int { return (int(a)+6)%7 - (int(b)+6)%7 })
+	return r, nil
+}
@@ -0,0 +1,213 @@
+// Package issue gives typed access to the custom fields of the OCPBUGS
+// issues.
+package issue
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+)
+
+// Issue is a Jira issue whose custom fields are resolved with a field
+// registry. The accessors return an error when the value of the field has an
+// unexpected shape; an empty field is not an error.
+type Issue struct {
+	jira.Issue
+	fields *fields.Registry
+}
+
+// New wraps issue. f must hold the fields read by the accessors called on the
+// result.
+func New(issue jira.Issue, f *fields.Registry) Issue {
+	return Issue{Issue: issue, fields: f}
+}
+
+const (
+	ReleaseBlockerNone     ReleaseBlocker = ""
+	ReleaseBlockerApproved ReleaseBlocker = "Approved"
+	ReleaseBlockerProposed ReleaseBlocker = "Proposed"
+	ReleaseBlockerRejected ReleaseBlocker = "Rejected"
+)
+
+// ReleaseBlocker is the value of the Release Blocker field.
+type ReleaseBlocker string
+
+const (
+	TestCoverageNone       TestCoverage = 0
+	TestCoverageAutomated  TestCoverage = '+'
+	TestCoverageManual     TestCoverage = '-'
+	TestCoverageNoCoverage TestCoverage = '?'
+)
+
+// TestCoverage is the value of the Test Coverage field.
+type TestCoverage byte
+
+// unknown returns the raw value of the named custom field, or nil if it is
+// empty.
+func (i Issue) unknown(name string) any {
+	if i.Fields == nil {
+		return nil
+	}
+	return i.Fields.Unknowns[i.fields.ID(name)]
+}
+
+// option returns the ID and the value of the option selected in the named
+// single-select field.
+func (i Issue) option(name string) (id, value string, err error) {
+	raw := i.unknown(name)
+	if raw == nil {
+		return "", "", nil
+	}
+	return parseOption(name, raw)
+}
+
+func parseOption(name string, raw any) (id, value string, err error) {
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return "", "", fmt.Errorf("failed to parse %s: not an option", name)
+	}
+	id, ok = m["id"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("failed to parse %s: option without ID", name)
+	}
+	value, _ = m["value"].(string)
+	return id, value, nil
+}
+
+// list returns the elements of the named multi-value field.
+func (i Issue) list(name string) ([]map[string]any, error) {
+	raw := i.unknown(name)
+	if raw == nil {
+		return nil, nil
+	}
+	values, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse %s: not a list", name)
+	}
+	list := make([]map[string]any, 0, len(values))
+	for _, v := range values {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse %s: not a list of objects", name)
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+// text returns the value of the named text field.
+func (i Issue) text(name string) (string, error) {
+	raw := i.unknown(name)
+	if raw == nil {
+		return "", nil
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("failed to parse %s: not a string", name)
+	}
+	return s, nil
+}
+
+// ReleaseNoteType returns the value of the Release Note Type, e.g.
+// "Release Note Not Required".
+func (i Issue) ReleaseNoteType() (string, error) {
+	_, value, err := i.option(fields.ReleaseNoteType)
+	return value, err
+}
+
+// ReleaseNoteText returns the Release Note Text.
+func (i Issue) ReleaseNoteText() (string, error) {
+	return i.text(fields.ReleaseNoteText)
+}
+
+// ReleaseBlocker returns the Release Blocker status of the issue.
+func (i Issue) ReleaseBlocker() (ReleaseBlocker, error) {
+	id, value, err := i.option(fields.ReleaseBlocker)
+	if err != nil || id == "" {
+		return ReleaseBlockerNone, err
+	}
+
+	switch value {
+	case fields.ReleaseBlockerApproved:
+		return ReleaseBlockerApproved, nil
+	case fields.ReleaseBlockerProposed:
+		return ReleaseBlockerProposed, nil
+	case fields.ReleaseBlockerRejected:
+		return ReleaseBlockerRejected, nil
+	default:
+		return ReleaseBlockerNone, fmt.Errorf("unknown Release Blocker value: %q (%s)", value, id)
+	}
+}
+
+// TestCoverage returns the first value of the Test Coverage.
+func (i Issue) TestCoverage() (TestCoverage, error) {
+	values, err := i.list(fields.TestCoverage)
+	if err != nil || len(values) == 0 {
+		return TestCoverageNone, err
+	}
+
+	id, value, err := parseOption(fields.TestCoverage, values[0])
+	if err != nil {
+		return TestCoverageNone, err
+	}
+
+	switch value {
+	case fields.TestCoverageAutomated:
+		return TestCoverageAutomated, nil
+	case fields.TestCoverageManual:
+		return TestCoverageManual, nil
+	case fields.TestCoverageNone:
+		return TestCoverageNoCoverage, nil
+	default:
+		return TestCoverageNone, fmt.Errorf("unknown test coverage value: %q (%s)", value, id)
+	}
+}
+
+// CVEID returns the CVE identifier of a Vulnerability, e.g. "CVE-2024-1234".
+func (i Issue) CVEID() (string, error) {
+	cveID, err := i.text(fields.CVE)
+	return strings.TrimSpace(cveID), err
+}
+
+// NeedInfoFrom returns the account IDs of the users whose input is awaited.
+func (i Issue) NeedInfoFrom() ([]string, error) {
+	users, err := i.list(fields.NeedInfoFrom)
+	if err != nil {
+		return nil, err
+	}
+	accountIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		accountID, ok := user["accountId"].(string)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse %s: user without account ID", fields.NeedInfoFrom)
+		}
+		accountIDs = append(accountIDs, accountID)
+	}
+	return accountIDs, nil
+}
+
+// TargetVersion returns the names of the versions targeted by the fix, e.g.
+// "4.19.0".
+func (i Issue) TargetVersion() ([]string, error) {
+	versions, err := i.list(fields.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		name, ok := version["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse %s: version without name", fields.TargetVersion)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Severity returns the value of the Severity, e.g. "Important".
+func (i Issue) Severity() (string, error) {
+	_, value, err := i.option(fields.Severity)
+	return value, err
+}
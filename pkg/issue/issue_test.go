@@ -0,0 +1,122 @@
+package issue
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+)
+
+var registry = fields.New(
+	map[string]string{
+		fields.ReleaseNoteType: "customfield_10785",
+		fields.ReleaseNoteText: "customfield_10783",
+		fields.ReleaseBlocker:  "customfield_10847",
+		fields.TestCoverage:    "customfield_10638",
+		fields.CVE:             "customfield_10667",
+		fields.NeedInfoFrom:    "customfield_10400",
+		fields.TargetVersion:   "customfield_10855",
+		fields.Severity:        "customfield_10840",
+	},
+)
+
+// parse returns an issue with the custom fields given in JSON.
+func parse(t *testing.T, customFields string) Issue {
+	t.Helper()
+	var issue jira.Issue
+	if err := json.Unmarshal([]byte(`{"key": "OCPBUGS-1", "fields": `+customFields+`}`), &issue); err != nil {
+		t.Fatal(err)
+	}
+	return New(issue, registry)
+}
+
+func TestAccessors(t *testing.T) {
+	issue := parse(t, `{
+		"customfield_10785": {"id": "12510", "value": "Release Note Not Required"},
+		"customfield_10783": "Fixed the thing.",
+		"customfield_10847": {"id": "16773", "value": "Proposed"},
+		"customfield_10638": [{"id": "15875", "value": "+"}],
+		"customfield_10667": " CVE-2026-1234 ",
+		"customfield_10400": [{"accountId": "712020:aaaa"}, {"accountId": "712020:bbbb"}],
+		"customfield_10855": [{"id": "1", "name": "4.22.0"}],
+		"customfield_10840": {"id": "2", "value": "Important"}
+	}`)
+
+	if got, err := issue.ReleaseNoteType(); err != nil || got != "Release Note Not Required" {
+		t.Errorf("ReleaseNoteType: got %q, %v", got, err)
+	}
+	if got, err := issue.ReleaseNoteText(); err != nil || got != "Fixed the thing." {
+		t.Errorf("ReleaseNoteText: got %q, %v", got, err)
+	}
+	if got, err := issue.ReleaseBlocker(); err != nil || got != ReleaseBlockerProposed {
+		t.Errorf("ReleaseBlocker: got %q, %v", got, err)
+	}
+	if got, err := issue.TestCoverage(); err != nil || got != TestCoverageAutomated {
+		t.Errorf("TestCoverage: got %q, %v", got, err)
+	}
+	if got, err := issue.CVEID(); err != nil || got != "CVE-2026-1234" {
+		t.Errorf("CVEID: got %q, %v", got, err)
+	}
+	if got, err := issue.NeedInfoFrom(); err != nil || !slices.Equal(got, []string{"712020:aaaa", "712020:bbbb"}) {
+		t.Errorf("NeedInfoFrom: got %q, %v", got, err)
+	}
+	if got, err := issue.TargetVersion(); err != nil || !slices.Equal(got, []string{"4.22.0"}) {
+		t.Errorf("TargetVersion: got %q, %v", got, err)
+	}
+	if got, err := issue.Severity(); err != nil || got != "Important" {
+		t.Errorf("Severity: got %q, %v", got, err)
+	}
+}
+
+func TestAccessorsEmpty(t *testing.T) {
+	issue := parse(t, `{}`)
+
+	if got, err := issue.ReleaseNoteType(); err != nil || got != "" {
+		t.Errorf("ReleaseNoteType: got %q, %v", got, err)
+	}
+	if got, err := issue.ReleaseBlocker(); err != nil || got != ReleaseBlockerNone {
+		t.Errorf("ReleaseBlocker: got %q, %v", got, err)
+	}
+	if got, err := issue.TestCoverage(); err != nil || got != TestCoverageNone {
+		t.Errorf("TestCoverage: got %q, %v", got, err)
+	}
+	if got, err := issue.NeedInfoFrom(); err != nil || len(got) != 0 {
+		t.Errorf("NeedInfoFrom: got %q, %v", got, err)
+	}
+}
+
+func TestAccessorsErrors(t *testing.T) {
+	issue := parse(t, `{
+		"customfield_10785": "Release Note Not Required",
+		"customfield_10783": {"text": "Fixed the thing."},
+		"customfield_10847": {"id": "99999", "value": "Maybe"},
+		"customfield_10638": {"id": "15875", "value": "+"},
+		"customfield_10667": 1234,
+		"customfield_10400": [{"name": "user"}],
+		"customfield_10855": ["4.22.0"]
+	}`)
+
+	if _, err := issue.ReleaseNoteType(); err == nil {
+		t.Error("ReleaseNoteType: expected an error")
+	}
+	if _, err := issue.ReleaseNoteText(); err == nil {
+		t.Error("ReleaseNoteText: expected an error")
+	}
+	if _, err := issue.ReleaseBlocker(); err == nil {
+		t.Error("ReleaseBlocker: expected an error")
+	}
+	if _, err := issue.TestCoverage(); err == nil {
+		t.Error("TestCoverage: expected an error")
+	}
+	if _, err := issue.CVEID(); err == nil {
+		t.Error("CVEID: expected an error")
+	}
+	if _, err := issue.NeedInfoFrom(); err == nil {
+		t.Error("NeedInfoFrom: expected an error")
+	}
+	if _, err := issue.TargetVersion(); err == nil {
+		t.Error("TargetVersion: expected an error")
+	}
+}
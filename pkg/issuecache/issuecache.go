@@ -0,0 +1,496 @@
+// Package issuecache keeps a local copy of the Jira issues in a JSON file, so
+// that each run only downloads the issues updated since the previous one.
+//
+// The cache holds the issues of a scope, e.g. the bugs of the team, with all
+// their fields. It is synced incrementally with the issues of the scope
+// updated since the last sync; the IDs of the issues of the scope are listed
+// too, to forget the issues that were deleted or left the scope.
+//
+// A search for the scope itself is served from the cache without asking Jira.
+// Other searches ask Jira which issues match their JQL, requesting only the
+// update time of each issue, and the issues are served from the cache unless
+// they changed.
+package issuecache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// Version is the version of the format of the cache file. A file with another
+// version is discarded.
+const Version = 1
+
+// syncMargin is subtracted from the time of the last sync to cover the clock
+// skew between Jira and bugwatcher.
+const syncMargin = 5 * time.Minute
+
+// pageSize is the number of issues fetched by each search request, and batchSize
+// the number of issues fetched by ID at once.
+const (
+	pageSize  = 100
+	batchSize = 100
+)
+
+// Entry is a cached issue.
+type Entry struct {
+	Key string `json:"key"`
+	// Updated is the update time of the issue, as formatted by Jira.
+	Updated string `json:"updated"`
+	// Issue is the issue as returned by Jira.
+	Issue json.RawMessage `json:"issue"`
+}
+
+// file is the content of the cache file.
+type file struct {
+	Version  int       `json:"version"`
+	BaseURL  string    `json:"base_url"`
+	Scope    string    `json:"scope"`
+	LastSync time.Time `json:"last_sync,omitzero"`
+	// Issues are indexed by ID.
+	Issues map[string]Entry `json:"issues"`
+}
+
+// Cache is the local copy of the issues of a scope. It is safe for concurrent
+// use.
+type Cache struct {
+	path string
+
+	mu     sync.Mutex
+	data   file
+	synced bool
+	dirty  bool
+	// members are the IDs of the issues of the scope at the last sync, in
+	// the order returned by Jira.
+	members []string
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// Open reads the cache file at path, holding the issues of the Jira instance
+// at baseURL that match the scope JQL. The cache starts empty if the file
+// does not exist, or if it was written for another Jira instance, scope or
+// format.
+func Open(path, baseURL, scope string) (*Cache, error) {
+	c := &Cache{
+		path: path,
+		data: file{Version: Version, BaseURL: baseURL, Scope: scope, Issues: make(map[string]Entry)},
+		now:  time.Now,
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the issue cache: %w", err)
+	}
+
+	var data file
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("error decoding the issue cache %q: %w", path, err)
+	}
+	switch {
+	case data.Version != Version:
+		log.Printf("The issue cache %q has version %d instead of %d: starting over", path, data.Version, Version)
+	case data.BaseURL != baseURL || data.Scope != scope:
+		log.Printf("The issue cache %q holds another Jira instance or scope: starting over", path)
+	default:
+		if data.Issues == nil {
+			data.Issues = make(map[string]Entry)
+		}
+		c.data = data
+	}
+	return c, nil
+}
+
+// Path returns the path of the cache file.
+func (c *Cache) Path() string { return c.path }
+
+// LastSync returns the time of the last successful sync, or the zero time if
+// the cache was never synced.
+func (c *Cache) LastSync() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.LastSync
+}
+
+// Entries returns the cached issues, sorted by key.
+func (c *Cache) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]Entry, 0, len(c.data.Issues))
+	for _, e := range c.data.Issues {
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return compareKeys(a.Key, b.Key) })
+	return entries
+}
+
+// Entry returns the cached issue with the given key.
+func (c *Cache) Entry(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range c.data.Issues {
+		if e.Key == key {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Clear empties the cache, so that the next sync downloads the whole scope.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data.Issues = make(map[string]Entry)
+	c.data.LastSync = time.Time{}
+	c.synced = false
+	c.members = nil
+	c.dirty = true
+}
+
+// Sync downloads the issues of the scope updated since the last sync, or all
+// of them if the cache is empty. It then lists the IDs of the issues of the
+// scope, downloads those that are missing, and removes the cached issues that
+// are no longer in the scope.
+func (c *Cache) Sync(ctx context.Context, client *jira.Client) error {
+	start := c.now()
+
+	c.mu.Lock()
+	lastSync := c.data.LastSync
+	full := lastSync.IsZero()
+	jql := c.data.Scope
+	c.mu.Unlock()
+
+	if !full {
+		// A relative date does not depend on the time zone of the Jira user.
+		minutes := math.Ceil(start.Sub(lastSync.Add(-syncMargin)).Minutes())
+		jql = "(" + strings.TrimSpace(jql) + `) AND updated >= "-` + strconv.FormatFloat(minutes, 'f', 0, 64) + `m"`
+	}
+
+	var members []string
+	for raw, err := range search(ctx, client, jql, "*all") {
+		if err != nil {
+			return fmt.Errorf("error syncing the issue cache: %w", err)
+		}
+		id, err := c.store(raw)
+		if err != nil {
+			return err
+		}
+		members = append(members, id)
+	}
+	log.Printf("Synced %d issues to the issue cache", len(members))
+
+	if !full {
+		// The issues that left the scope are not returned by the query
+		// of the updated issues: list the whole scope, by ID only.
+		var err error
+		if members, err = c.listScope(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	isMember := make(map[string]bool, len(members))
+	for _, id := range members {
+		isMember[id] = true
+	}
+	var pruned int
+	for id := range c.data.Issues {
+		if !isMember[id] {
+			delete(c.data.Issues, id)
+			pruned++
+		}
+	}
+	if pruned > 0 {
+		log.Printf("Removed %d issues out of the scope from the issue cache", pruned)
+	}
+	c.members = members
+	c.data.LastSync = start
+	c.synced = true
+	c.dirty = true
+	return nil
+}
+
+// listScope returns the IDs of the issues of the scope, and downloads those
+// that are missing from the cache.
+func (c *Cache) listScope(ctx context.Context, client *jira.Client) ([]string, error) {
+	c.mu.Lock()
+	jql := c.data.Scope
+	c.mu.Unlock()
+
+	var ids, missing []string
+	for raw, err := range search(ctx, client, jql, "id") {
+		if err != nil {
+			return nil, fmt.Errorf("error syncing the issue cache: %w", err)
+		}
+		var header issueHeader
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return nil, fmt.Errorf("error decoding an issue: %w", err)
+		}
+		ids = append(ids, header.ID)
+
+		c.mu.Lock()
+		_, ok := c.data.Issues[header.ID]
+		c.mu.Unlock()
+		if !ok {
+			missing = append(missing, header.ID)
+		}
+	}
+	if err := c.download(ctx, client, missing); err != nil {
+		return nil, fmt.Errorf("error syncing the issue cache: %w", err)
+	}
+	return ids, nil
+}
+
+// download fetches the issues with the given IDs into the cache.
+func (c *Cache) download(ctx context.Context, client *jira.Client, ids []string) error {
+	for batch := range slices.Chunk(ids, batchSize) {
+		for raw, err := range search(ctx, client, "id in ("+strings.Join(batch, ",")+")", "*all") {
+			if err != nil {
+				return err
+			}
+			if _, err := c.store(raw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncOnce syncs the cache on the first call.
+func (c *Cache) syncOnce(ctx context.Context, client *jira.Client) error {
+	c.mu.Lock()
+	synced := c.synced
+	c.mu.Unlock()
+
+	if synced {
+		return nil
+	}
+	return c.Sync(ctx, client)
+}
+
+// store adds or replaces the issue in the cache, and returns its ID.
+func (c *Cache) store(raw json.RawMessage) (string, error) {
+	var header issueHeader
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return "", fmt.Errorf("error decoding an issue: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Issues[header.ID] = Entry{Key: header.Key, Updated: header.Fields.Updated, Issue: raw}
+	c.dirty = true
+	return header.ID, nil
+}
+
+// Search returns an iterator over the issues matching the JQL query, in the
+// order returned by Jira. The cache is synced before the first search. The
+// issues of the scope are served from the cache, as listed by the sync. For
+// any other query, Jira is asked for the IDs and update times of the matching
+// issues; those that are missing from the cache, or that changed, are
+// downloaded. Jira remains the judge of which issues match, so the results are
+// never stale.
+func (c *Cache) Search(ctx context.Context, client *jira.Client, jql string) iter.Seq2[jira.Issue, error] {
+	return func(yield func(jira.Issue, error) bool) {
+		if err := c.syncOnce(ctx, client); err != nil {
+			yield(jira.Issue{}, err)
+			return
+		}
+
+		c.mu.Lock()
+		scope := sameQuery(jql, c.data.Scope)
+		members := c.members
+		c.mu.Unlock()
+		if scope {
+			log.Printf("Served %d issues of the scope from the issue cache", len(members))
+			c.serve(members, yield)
+			return
+		}
+
+		var (
+			ids     []string
+			missing []string
+		)
+		for raw, err := range search(ctx, client, jql, "updated") {
+			if err != nil {
+				yield(jira.Issue{}, err)
+				return
+			}
+			var header issueHeader
+			if err := json.Unmarshal(raw, &header); err != nil {
+				yield(jira.Issue{}, fmt.Errorf("error decoding an issue: %w", err))
+				return
+			}
+			ids = append(ids, header.ID)
+
+			c.mu.Lock()
+			entry, ok := c.data.Issues[header.ID]
+			c.mu.Unlock()
+			if !ok || entry.Updated != header.Fields.Updated {
+				missing = append(missing, header.ID)
+			}
+		}
+
+		if err := c.download(ctx, client, missing); err != nil {
+			yield(jira.Issue{}, err)
+			return
+		}
+		if len(ids) > 0 {
+			log.Printf("Served %d issues, %d of them from the issue cache", len(ids), len(ids)-len(missing))
+		}
+		c.serve(ids, yield)
+	}
+}
+
+// serve yields the cached issues with the given IDs, skipping those that are
+// not in the cache.
+func (c *Cache) serve(ids []string, yield func(jira.Issue, error) bool) {
+	for _, id := range ids {
+		c.mu.Lock()
+		entry, ok := c.data.Issues[id]
+		c.mu.Unlock()
+		if !ok {
+			// The issue was deleted or moved out of sight meanwhile.
+			continue
+		}
+
+		var issue jira.Issue
+		if err := json.Unmarshal(entry.Issue, &issue); err != nil {
+			yield(jira.Issue{}, fmt.Errorf("error decoding the cached issue %s: %w", entry.Key, err))
+			return
+		}
+		if !yield(issue, nil) {
+			return
+		}
+	}
+}
+
+// sameQuery reports whether the JQL queries only differ by white space.
+func sameQuery(a, b string) bool {
+	return slices.Equal(strings.Fields(a), strings.Fields(b))
+}
+
+// Save writes the cache file, if the cache changed.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	b, err := json.Marshal(c.data)
+	if err != nil {
+		return fmt.Errorf("error encoding the issue cache: %w", err)
+	}
+
+	// Write then rename, so that an interrupted run does not leave a
+	// truncated cache behind.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error writing the issue cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing the issue cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing the issue cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("error writing the issue cache: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// issueHeader holds the fields of the issues that the cache reads.
+type issueHeader struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Updated string `json:"updated"`
+	} `json:"fields"`
+}
+
+// searchPage is a page of search results, with the issues left undecoded so
+// that they are cached as returned by Jira.
+type searchPage struct {
+	Issues        []json.RawMessage `json:"issues"`
+	IsLast        bool              `json:"isLast"`
+	NextPageToken string            `json:"nextPageToken"`
+}
+
+// search returns an iterator over the raw issues matching the JQL query.
+func search(ctx context.Context, client *jira.Client, jql, fields string) iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		params := url.Values{
+			"jql":        {jql},
+			"fields":     {fields},
+			"maxResults": {strconv.Itoa(pageSize)},
+		}
+		for {
+			req, err := client.NewRequestWithContext(ctx, http.MethodGet, "rest/api/2/search/jql?"+params.Encode(), nil)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			var page searchPage
+			if _, err := client.Do(req, &page); err != nil {
+				yield(nil, fmt.Errorf("error fetching issues: %w", err))
+				return
+			}
+
+			for _, raw := range page.Issues {
+				if !yield(raw, nil) {
+					return
+				}
+			}
+
+			if page.IsLast || page.NextPageToken == "" {
+				return
+			}
+			params.Set("nextPageToken", page.NextPageToken)
+		}
+	}
+}
+
+// compareKeys orders issue keys by project, then by number.
+func compareKeys(a, b string) int {
+	projectA, numberA, _ := strings.Cut(a, "-")
+	projectB, numberB, _ := strings.Cut(b, "-")
+	if c := strings.Compare(projectA, projectB); c != 0 {
+		return c
+	}
+	na, _ := strconv.Atoi(numberA)
+	nb, _ := strconv.Atoi(numberB)
+	if na != nb {
+		return na - nb
+	}
+	return strings.Compare(a, b)
+}
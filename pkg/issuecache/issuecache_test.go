@@ -0,0 +1,342 @@
+package issuecache
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/jiratest"
+)
+
+const scope = "project = OCPBUGS"
+
+// newServer returns a Jira server holding three issues, and a client that
+// records the JQL of its searches. The filter of the server matches the
+// "id in (...)" queries of the cache, and every other query to all the
+// issues.
+func newServer(t *testing.T) (*jiratest.Server, *jira.Client, *recorder) {
+	t.Helper()
+
+	server := jiratest.NewServer(t)
+	server.Seed(jiratest.Fixture{Issues: []map[string]any{
+		{"id": "10001", "key": "OCPBUGS-1", "fields": map[string]any{"summary": "one", "updated": "2025-01-01T10:00:00.000+0000", "customfield_10847": "foo"}},
+		{"id": "10002", "key": "OCPBUGS-2", "fields": map[string]any{"summary": "two", "updated": "2025-01-01T11:00:00.000+0000"}},
+		{"id": "10010", "key": "OCPBUGS-10", "fields": map[string]any{"summary": "ten", "updated": "2025-01-01T12:00:00.000+0000"}},
+	}})
+	idIn := regexp.MustCompile(`^id in \((.*)\)$`)
+	server.Filter = func(jql string, issue jira.Issue) bool {
+		if m := idIn.FindStringSubmatch(jql); m != nil {
+			return slices.Contains(strings.Split(m[1], ","), issue.ID)
+		}
+		return true
+	}
+
+	r := &recorder{next: http.DefaultTransport}
+	client, err := jira.NewClient(&http.Client{Transport: r}, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, client, r
+}
+
+// recorder records the JQL of the search requests.
+type recorder struct {
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	queries []string
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/search/jql") {
+		r.mu.Lock()
+		r.queries = append(r.queries, req.URL.Query().Get("jql"))
+		r.mu.Unlock()
+	}
+	return r.next.RoundTrip(req)
+}
+
+// Queries returns the JQL of the searches, in order.
+func (r *recorder) Queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.queries)
+}
+
+func searchAll(t *testing.T, cache *Cache, client *jira.Client, jql string) []jira.Issue {
+	t.Helper()
+
+	var issues []jira.Issue
+	for issue, err := range cache.Search(context.Background(), client, jql) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func TestSearch(t *testing.T) {
+	server, client, r := newServer(t)
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	cache, err := Open(path, server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := searchAll(t, cache, client, "assignee is EMPTY")
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %d", len(issues))
+	}
+	if got := issues[0].Fields.Unknowns["customfield_10847"]; got != "foo" {
+		t.Errorf("expected the custom field to be cached, got %v", got)
+	}
+	if got, want := r.Queries(), []string{scope, "assignee is EMPTY"}; !slices.Equal(got, want) {
+		t.Errorf("expected the queries %q, got %q", want, got)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the cache, and change an issue.
+	cache, err = Open(path, server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.LastSync().IsZero() {
+		t.Errorf("expected the time of the last sync to be saved")
+	}
+	if got := len(cache.Entries()); got != 3 {
+		t.Errorf("expected 3 cached issues, got %d", got)
+	}
+	if _, err := client.Issue.UpdateIssue("OCPBUGS-2", map[string]any{"fields": map[string]any{"summary": "two, updated"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	issues = searchAll(t, cache, client, "assignee is EMPTY")
+	var summaries []string
+	for _, issue := range issues {
+		summaries = append(summaries, issue.Fields.Summary)
+	}
+	if want := []string{"one", "two, updated", "ten"}; !slices.Equal(summaries, want) {
+		t.Errorf("expected the summaries %q, got %q", want, summaries)
+	}
+
+	// The incremental sync downloads the changed issue and lists the scope,
+	// so that the search is served from the cache.
+	got := r.Queries()[2:]
+	if len(got) != 3 {
+		t.Fatalf("expected 3 more queries, got %q", got)
+	}
+	if !regexp.MustCompile(`^\(project = OCPBUGS\) AND updated >= "-\d+m"$`).MatchString(got[0]) {
+		t.Errorf("expected an incremental sync, got %q", got[0])
+	}
+	if got[1] != scope {
+		t.Errorf("expected the scope to be listed, got %q", got[1])
+	}
+	if got[2] != "assignee is EMPTY" {
+		t.Errorf("expected the search query, got %q", got[2])
+	}
+}
+
+func TestSearchScope(t *testing.T) {
+	server, client, r := newServer(t)
+
+	cache, err := Open(filepath.Join(t.TempDir(), "cache.json"), server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	before := len(r.Queries())
+
+	issues := searchAll(t, cache, client, "  project =\n\tOCPBUGS\n")
+	var keys []string
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	if want := []string{"OCPBUGS-1", "OCPBUGS-2", "OCPBUGS-10"}; !slices.Equal(keys, want) {
+		t.Errorf("expected the keys %q, got %q", want, keys)
+	}
+	if got := r.Queries()[before:]; len(got) > 0 {
+		t.Errorf("expected the scope to be served from the cache, got the queries %q", got)
+	}
+}
+
+func TestSearchDownloadsChangedIssues(t *testing.T) {
+	server, client, r := newServer(t)
+
+	cache, err := Open(filepath.Join(t.TempDir(), "cache.json"), server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchAll(t, cache, client, "assignee is EMPTY")
+
+	// The cache is only synced once per run: the change is detected by the
+	// update time returned by the search.
+	if _, err := client.Issue.UpdateIssue("OCPBUGS-10", map[string]any{"fields": map[string]any{"summary": "ten, updated"}}); err != nil {
+		t.Fatal(err)
+	}
+	issues := searchAll(t, cache, client, "assignee is EMPTY")
+	if got := issues[2].Fields.Summary; got != "ten, updated" {
+		t.Errorf("expected the updated summary, got %q", got)
+	}
+	if got, want := r.Queries()[2:], []string{"assignee is EMPTY", "id in (10010)"}; !slices.Equal(got, want) {
+		t.Errorf("expected the queries %q, got %q", want, got)
+	}
+}
+
+func TestSearchStops(t *testing.T) {
+	server, client, _ := newServer(t)
+
+	cache, err := Open(filepath.Join(t.TempDir(), "cache.json"), server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for _, err := range cache.Search(context.Background(), client, "assignee is EMPTY") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("expected the iteration to stop after 1 issue, got %d", n)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	server, client, _ := newServer(t)
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	cache, err := Open(path, server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range [...]struct {
+		name    string
+		baseURL string
+		scope   string
+		entries int
+	}{
+		{"same scope", server.URL, scope, 3},
+		{"other scope", server.URL, "project = OSASINFRA", 0},
+		{"other instance", "https://jira.example.com", scope, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cache, err := Open(path, tc.baseURL, tc.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(cache.Entries()); got != tc.entries {
+				t.Errorf("expected %d cached issues, got %d", tc.entries, got)
+			}
+			if got := cache.LastSync().IsZero(); got != (tc.entries == 0) {
+				t.Errorf("unexpected time of the last sync: %v", cache.LastSync())
+			}
+		})
+	}
+}
+
+func TestSyncWindow(t *testing.T) {
+	server, client, r := newServer(t)
+
+	cache, err := Open(filepath.Join(t.TempDir(), "cache.json"), server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour + 30*time.Second)
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Queries()[1], `(project = OCPBUGS) AND updated >= "-66m"`; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	cache.Clear()
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Queries()[3:]; !slices.Equal(got, []string{scope}) {
+		t.Errorf("expected a full sync after Clear, got %q", got)
+	}
+}
+
+func TestSyncPrunes(t *testing.T) {
+	server, client, r := newServer(t)
+
+	cache, err := Open(filepath.Join(t.TempDir(), "cache.json"), server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+
+	// OCPBUGS-2 leaves the scope, and the incremental sync downloads none
+	// of the issues.
+	server.Filter = func(jql string, issue jira.Issue) bool {
+		return issue.Key != "OCPBUGS-2" && !strings.Contains(jql, "updated >=")
+	}
+
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Queries()[2]; got != scope {
+		t.Errorf("expected the scope to be listed, got %q", got)
+	}
+	if _, ok := cache.Entry("OCPBUGS-2"); ok {
+		t.Errorf("expected the sync to remove OCPBUGS-2")
+	}
+	if got := len(cache.Entries()); got != 2 {
+		t.Errorf("expected 2 cached issues, got %d", got)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	server, client, _ := newServer(t)
+
+	cache, err := Open(filepath.Join(t.TempDir(), "cache.json"), server.URL, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Sync(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, e := range cache.Entries() {
+		keys = append(keys, e.Key)
+	}
+	if want := []string{"OCPBUGS-1", "OCPBUGS-2", "OCPBUGS-10"}; !slices.Equal(keys, want) {
+		t.Errorf("expected the keys %q, got %q", want, keys)
+	}
+	if e, ok := cache.Entry("OCPBUGS-2"); !ok || e.Updated != "2025-01-01T11:00:00.000+0000" {
+		t.Errorf("unexpected entry: %+v, %t", e, ok)
+	}
+}
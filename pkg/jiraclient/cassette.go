@@ -0,0 +1,267 @@
+package jiraclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// Cassette holds the HTTP interactions with Jira recorded during a run.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request to Jira and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a redacted HTTP request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a redacted HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// redacted replaces the secrets in the recorded headers.
+const redacted = "REDACTED"
+
+// keptHeaders are the headers recorded, in addition to the redacted
+// Authorization header.
+var keptHeaders = []string{"Content-Type", "Retry-After"}
+
+var (
+	emailPattern    = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	redactedPattern = regexp.MustCompile(`^redacted-[0-9a-f]{8}@example\.com$`)
+)
+
+// redactEmails replaces the email addresses in s with stable placeholders, so
+// that the same address is always replaced the same way, at recording and at
+// replay time.
+func redactEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		if redactedPattern.MatchString(email) {
+			return email
+		}
+		sum := sha256.Sum256([]byte(email))
+		return "redacted-" + hex.EncodeToString(sum[:4]) + "@example.com"
+	})
+}
+
+// requestKey returns the redacted path, query and body of the request, which
+// identify its recorded response independently of the Jira base URL.
+func requestKey(method string, u *url.URL, body string) string {
+	return method + " " + redactEmails(u.Path+"?"+u.Query().Encode()) + "\n" + body
+}
+
+// readBody returns the body of the request and restores it, so that the
+// request can still be sent.
+func readBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return string(b), nil
+}
+
+func redactHeader(h http.Header, auth bool) http.Header {
+	redactedHeader := make(http.Header)
+	for _, name := range keptHeaders {
+		if v := h.Values(name); len(v) > 0 {
+			redactedHeader[name] = v
+		}
+	}
+	if auth && h.Get("Authorization") != "" {
+		redactedHeader.Set("Authorization", redacted)
+	}
+	if len(redactedHeader) == 0 {
+		return nil
+	}
+	return redactedHeader
+}
+
+// Recorder keeps the interactions with Jira in memory, and saves them to a
+// cassette file when Save is called. It is safe for concurrent use.
+type Recorder struct {
+	path string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a recorder saving to the cassette file at path. It
+// writes an empty cassette, to fail early if the file cannot be written.
+func NewRecorder(path string) (*Recorder, error) {
+	r := &Recorder{path: path}
+	if err := r.Save(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Save writes the interactions recorded so far to the cassette file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding the cassette: %w", err)
+	}
+
+	// Write then rename, so that an interrupted run does not leave a
+	// truncated cassette behind.
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error writing the cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing the cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing the cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("error writing the cassette: %w", err)
+	}
+	return nil
+}
+
+// recordingTransport is a transport that adds its interactions to a recorder.
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	u := *req.URL
+	u.User = nil
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    redactEmails(u.String()),
+			Header: redactHeader(req.Header, true),
+			Body:   redactEmails(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     redactHeader(res.Header, false),
+			Body:       redactEmails(string(resBody)),
+		},
+	}
+
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.recorder.cassette.Interactions = append(t.recorder.cassette.Interactions, interaction)
+	return res, nil
+}
+
+// replayer is a transport that serves the interactions of a cassette. Each
+// recorded interaction is served once, in the recorded order; the last one
+// matching a request is served again when the others are exhausted.
+type replayer struct {
+	mu     sync.Mutex
+	served map[string]int
+	byKey  map[string][]Interaction
+}
+
+func newReplayer(path string) (*replayer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(b, &cassette); err != nil {
+		return nil, fmt.Errorf("error decoding the cassette %q: %w", path, err)
+	}
+
+	r := &replayer{
+		served: make(map[string]int),
+		byKey:  make(map[string][]Interaction),
+	}
+	for _, interaction := range cassette.Interactions {
+		u, err := url.Parse(interaction.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the cassette %q: %w", path, err)
+		}
+		key := requestKey(interaction.Request.Method, u, interaction.Request.Body)
+		r.byKey[key] = append(r.byKey[key], interaction)
+	}
+	return r, nil
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := requestKey(req.Method, req.URL, redactEmails(body))
+
+	r.mu.Lock()
+	interactions := r.byKey[key]
+	i := min(r.served[key], len(interactions)-1)
+	r.served[key]++
+	r.mu.Unlock()
+
+	if len(interactions) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL.RequestURI())
+	}
+
+	recorded := interactions[i].Response
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package jiraclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/jiratest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	server := jiratest.NewServer(t)
+	server.Seed(jiratest.Fixture{
+		Issues: []map[string]any{{
+			"id":  "10001",
+			"key": "OCPBUGS-1",
+			"fields": map[string]any{
+				"assignee":          map[string]any{"accountId": "acct-alice", "emailAddress": "alice@redhat.com"},
+				"customfield_10847": map[string]any{"id": "16773", "value": "Proposed"},
+			},
+		}},
+	})
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	recorder, err := NewRecorder(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recording, err := NewWithToken(server.URL, "bugwatcher@redhat.com", "s3cr3t-t0k3n", RecordTo(recorder))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorded, _, err := recording.Issue.SearchV2JQLWithContext(ctx, `reporter = "bob@redhat.com"`, &jira.SearchOptionsV2{MaxResults: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := recording.Issue.UpdateAssigneeWithContext(ctx, "10001", &jira.User{AccountID: "acct-bob"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The interactions are only written by Save.
+	b, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "OCPBUGS-1") {
+		t.Error("expected the cassette to be written on Save only")
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	b, err = os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cr3t-t0k3n", "alice@redhat.com", "bob@redhat.com", "bugwatcher@redhat.com", "Basic "} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("the cassette contains %q", secret)
+		}
+	}
+	if !strings.Contains(string(b), `"Authorization": [`) {
+		t.Error("expected the Authorization header to be recorded as redacted")
+	}
+
+	server.Close()
+
+	replaying, err := NewWithToken(server.URL, "", "", ReplayFrom(cassette))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replayed, _, err := replaying.Issue.SearchV2JQLWithContext(ctx, `reporter = "bob@redhat.com"`, &jira.SearchOptionsV2{MaxResults: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(replayed) != len(recorded) || replayed[0].Key != "OCPBUGS-1" {
+		t.Fatalf("expected the recorded issues, got %+v", replayed)
+	}
+	if got := replayed[0].Fields.Unknowns["customfield_10847"]; got.(map[string]any)["value"] != "Proposed" {
+		t.Errorf("expected the custom field to be replayed, got %v", got)
+	}
+	if email := replayed[0].Fields.Assignee.EmailAddress; !strings.HasSuffix(email, "@example.com") {
+		t.Errorf("expected a redacted email, got %q", email)
+	}
+	res, err := replaying.Issue.UpdateAssigneeWithContext(ctx, "10001", &jira.User{AccountID: "acct-bob"})
+	if err != nil || res.StatusCode != 204 {
+		t.Errorf("expected the recorded 204, got %v, %v", res, err)
+	}
+
+	if _, _, err := replaying.Issue.GetWithContext(ctx, "OCPBUGS-2", nil); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected an error for an unrecorded request, got %v", err)
+	}
+}
@@ -1,40 +1,63 @@
 package jiraclient
 
 import (
-	"log"
 	"net/http"
-	"strconv"
-	"time"
 
 	jira "github.com/andygrunwald/go-jira"
 )
 
-type throttlingHttpClient struct {
-	*http.Client
+// Option configures the Jira client.
+type Option func(*options)
+
+type options struct {
+	recordTo    *Recorder
+	replayFrom  string
+	retryPolicy RetryPolicy
+	limiter     *Limiter
 }
 
-func (c *throttlingHttpClient) Do(req *http.Request) (*http.Response, error) {
-	res, err := c.Client.Do(req)
+// RecordTo adds the requests to Jira and their responses to the recorder,
+// with the credentials and the email addresses redacted.
+func RecordTo(r *Recorder) Option {
+	return func(o *options) { o.recordTo = r }
+}
 
-	if res.StatusCode == http.StatusTooManyRequests {
-		wait := time.Second * 1
-		if retryAfter := res.Header.Get("retry-after"); retryAfter != "" {
-			if n, err := strconv.Atoi(retryAfter); err != nil {
-				wait = time.Duration(n) * time.Second
-			}
-		}
-		log.Printf("Throttled by Jira: waiting %s", wait)
-		time.Sleep(wait)
-		return c.Do(req)
+// ReplayFrom serves the responses recorded in a cassette file instead of
+// contacting Jira. The credentials are ignored.
+func ReplayFrom(path string) Option {
+	return func(o *options) { o.replayFrom = path }
+}
+
+// NewWithToken returns a Jira client that limits the rate of its requests and
+// retries the throttled and failed ones. By default, the rate is limited to
+// DefaultRate and the requests are retried according to DefaultRetryPolicy.
+func NewWithToken(baseURL, jiraEmail, jiraToken string, opts ...Option) (jiraClient *jira.Client, err error) {
+	o := options{
+		retryPolicy: DefaultRetryPolicy,
+		limiter:     NewLimiter(DefaultRate, DefaultBurst),
+	}
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	return res, err
-}
+	if o.replayFrom != "" {
+		replayer, err := newReplayer(o.replayFrom)
+		if err != nil {
+			return nil, err
+		}
+		return jira.NewClient(&http.Client{Transport: replayer}, baseURL)
+	}
 
-// NewWithToken returns a Jira client that retries when hitting 429
-func NewWithToken(baseURL, jiraEmail, jiraToken string) (jiraClient *jira.Client, err error) {
-	return jira.NewClient(
-		&throttlingHttpClient{(&jira.BasicAuthTransport{Username: jiraEmail, Password: jiraToken}).Client()},
-		baseURL,
-	)
+	// Each attempt waits for the limiter. The retries happen beneath the
+	// recorder, which only saves the final response of each request.
+	var next http.RoundTripper = http.DefaultTransport
+	if o.limiter != nil {
+		next = &limitTransport{next: next, limiter: o.limiter}
+	}
+	next = newRetryTransport(next, o.retryPolicy)
+	if o.recordTo != nil {
+		next = &recordingTransport{recorder: o.recordTo, next: next}
+	}
+	transport := &jira.BasicAuthTransport{Username: jiraEmail, Password: jiraToken, Transport: next}
+	return jira.NewClient(transport.Client(), baseURL)
 }
@@ -0,0 +1,172 @@
+package jiraclient
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default client-side rate limit of the requests to Jira.
+const (
+	DefaultRate  = 10
+	DefaultBurst = 20
+)
+
+// Headers sent by Jira Cloud to signal the rate limiting.
+const (
+	headerNearLimit = "X-RateLimit-NearLimit"
+	headerRemaining = "X-RateLimit-Remaining"
+)
+
+// Limiter is a token bucket shared by all the requests of a client.
+//
+// It adapts to Jira: the rate is halved when Jira answers 429 Too Many
+// Requests, reduced by a quarter when Jira warns that the limit is near, and
+// raised back progressively to the configured rate by the following
+// successful responses.
+type Limiter struct {
+	limit float64
+	burst int
+
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	// last is the time the tokens were last counted.
+	last time.Time
+	// pausedUntil delays all the requests after a 429.
+	pausedUntil time.Time
+
+	// now and sleep are replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewLimiter returns a limiter allowing rate requests per second on average,
+// and bursts of up to burst requests.
+func NewLimiter(rate float64, burst int) *Limiter {
+	burst = max(burst, 1)
+	return &Limiter{
+		limit:  rate,
+		burst:  burst,
+		rate:   rate,
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// WithRateLimit replaces the limiter built from DefaultRate and
+// DefaultBurst. A nil limiter disables the rate limiting.
+func WithRateLimit(limiter *Limiter) Option {
+	return func(o *options) { o.limiter = limiter }
+}
+
+// Rate returns the current rate of the limiter, in requests per second.
+func (l *Limiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Wait blocks until a request is allowed, or until the context is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		l.refill(now)
+
+		var wait time.Duration
+		switch {
+		case now.Before(l.pausedUntil):
+			wait = l.pausedUntil.Sub(now)
+		case l.tokens >= 1:
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		default:
+			wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		if err := l.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// refill adds the tokens accumulated since the last count.
+func (l *Limiter) refill(now time.Time) {
+	if now.Before(l.last) {
+		return
+	}
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, float64(l.burst))
+	l.last = now
+}
+
+// minRate returns the floor of the adaptive rate.
+func (l *Limiter) minRate() float64 {
+	return l.limit / 16
+}
+
+// Observe adapts the rate to the response of Jira.
+func (l *Limiter) Observe(res *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.refill(now)
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		l.rate = max(l.rate/2, l.minRate())
+		pause := parseRetryAfter(res.Header.Get("Retry-After"), now)
+		if pause == 0 {
+			pause = time.Duration(float64(time.Second) / l.rate)
+		}
+		if until := now.Add(pause); until.After(l.pausedUntil) {
+			l.pausedUntil = until
+		}
+		l.tokens = 0
+		l.last = l.pausedUntil
+		log.Printf("Throttled by Jira: pausing for %s, then slowing down to %.2f requests per second", pause.Round(time.Millisecond), l.rate)
+
+	case nearLimit(res.Header, l.burst):
+		if rate := max(l.rate*3/4, l.minRate()); rate < l.rate {
+			l.rate = rate
+			log.Printf("Jira rate limit is near: slowing down to %.2f requests per second", l.rate)
+		}
+
+	case res.StatusCode < http.StatusBadRequest && l.rate < l.limit:
+		l.rate = min(l.rate+l.limit/20, l.limit)
+	}
+}
+
+// nearLimit reports whether the rate limit headers of Jira warn that the
+// limit is about to be reached.
+func nearLimit(h http.Header, burst int) bool {
+	if near, err := strconv.ParseBool(h.Get(headerNearLimit)); err == nil && near {
+		return true
+	}
+	remaining, err := strconv.Atoi(h.Get(headerRemaining))
+	return err == nil && remaining < burst
+}
+
+// limitTransport waits for the limiter before each request.
+type limitTransport struct {
+	next    http.RoundTripper
+	limiter *Limiter
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	res, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.limiter.Observe(res)
+	}
+	return res, err
+}
@@ -0,0 +1,153 @@
+package jiraclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock advances when the limiter sleeps.
+type fakeClock struct {
+	mu    sync.Mutex
+	t     time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) sleep(_ context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	c.slept += d
+	return nil
+}
+
+func newTestLimiter(rate float64, burst int) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)}
+	l := NewLimiter(rate, burst)
+	l.now, l.sleep, l.last = clock.now, clock.sleep, clock.t
+	return l, clock
+}
+
+func response(code int, header ...string) *http.Response {
+	res := &http.Response{StatusCode: code, Header: make(http.Header)}
+	for i := 0; i+1 < len(header); i += 2 {
+		res.Header.Set(header[i], header[i+1])
+	}
+	return res
+}
+
+func TestLimiterWait(t *testing.T) {
+	l, clock := newTestLimiter(10, 5)
+	ctx := context.Background()
+
+	for range 5 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if clock.slept != 0 {
+		t.Errorf("expected the burst not to wait, waited %s", clock.slept)
+	}
+
+	for range 10 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if clock.slept != time.Second {
+		t.Errorf("expected 10 requests past the burst to take 1s at 10/s, took %s", clock.slept)
+	}
+}
+
+func TestLimiterAdapts(t *testing.T) {
+	l, clock := newTestLimiter(10, 5)
+	ctx := context.Background()
+
+	l.Observe(response(http.StatusTooManyRequests, "Retry-After", "3"))
+	if got := l.Rate(); got != 5 {
+		t.Errorf("expected the rate to be halved after a 429, got %v", got)
+	}
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clock.slept < 3*time.Second {
+		t.Errorf("expected to pause for Retry-After, waited %s", clock.slept)
+	}
+
+	l.Observe(response(http.StatusOK, "X-RateLimit-NearLimit", "true"))
+	if got := l.Rate(); got != 3.75 {
+		t.Errorf("expected the rate to slow down near the limit, got %v", got)
+	}
+	l.Observe(response(http.StatusOK, "X-RateLimit-Remaining", "2"))
+	if got := l.Rate(); got >= 3.75 {
+		t.Errorf("expected the rate to slow down with few remaining requests, got %v", got)
+	}
+
+	for range 100 {
+		l.Observe(response(http.StatusOK, "X-RateLimit-Remaining", "500"))
+	}
+	if got := l.Rate(); got != 10 {
+		t.Errorf("expected the rate to recover to the limit, got %v", got)
+	}
+
+	for range 100 {
+		l.Observe(response(http.StatusTooManyRequests))
+	}
+	if got, want := l.Rate(), 10.0/16; got != want {
+		t.Errorf("expected the rate not to go under %v, got %v", want, got)
+	}
+}
+
+func TestLimiterHonorsTheContext(t *testing.T) {
+	l := NewLimiter(0.001, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
+
+func TestLimiterIsShared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewWithToken(server.URL, "", "", WithRateLimit(NewLimiter(100, 5)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := client.NewRequest(http.MethodGet, "rest/api/2/myself", nil)
+			if _, err := client.Do(req, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 5 requests are sent at once, the 15 others at 100 per second.
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("expected the requests to be limited, took %s", elapsed)
+	}
+}
@@ -0,0 +1,202 @@
+package jiraclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy defines how failed requests to Jira are retried.
+//
+// Requests are retried on 429 Too Many Requests, 502 Bad Gateway, 503 Service
+// Unavailable, 504 Gateway Timeout and on transient network errors, with an
+// exponential backoff and jitter, or after the delay requested by the
+// Retry-After header.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	MaxAttempts int
+	// Budget is the maximum time spent on a request, including the waits
+	// between attempts.
+	Budget time.Duration
+	// BaseDelay is the wait before the second attempt, doubled before each
+	// following attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts, except when requested by
+	// Retry-After.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is passed.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 6,
+	Budget:      5 * time.Minute,
+	BaseDelay:   time.Second,
+	MaxDelay:    time.Minute,
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) { o.retryPolicy = policy }
+}
+
+// retryTransport retries the failed requests according to its policy.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy RetryPolicy
+
+	// now and sleep are replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newRetryTransport(next http.RoundTripper, policy RetryPolicy) *retryTransport {
+	return &retryTransport{
+		next:   next,
+		policy: policy,
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// sleep waits for d, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := t.now()
+
+	// The body is consumed by each attempt: it is read again from GetBody,
+	// which is set for the requests created by the Jira client, or buffered
+	// here otherwise.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading the request body: %w", err)
+		}
+		req = req.Clone(ctx)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			attemptReq = req.Clone(ctx)
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("error rewinding the request body: %w", err)
+			}
+			attemptReq.Body = body
+		}
+
+		res, err := t.next.RoundTrip(attemptReq)
+
+		reason, retryAfter, retry := t.shouldRetry(ctx, res, err)
+		if !retry {
+			return res, err
+		}
+
+		delay := t.backoff(attempt)
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+
+		if attempt >= t.policy.MaxAttempts {
+			log.Printf("Jira %s %s: attempt %d/%d failed (%s); giving up", req.Method, req.URL.Path, attempt, t.policy.MaxAttempts, reason)
+			return res, err
+		}
+		if elapsed := t.now().Sub(start); elapsed+delay > t.policy.Budget {
+			log.Printf("Jira %s %s: attempt %d/%d failed (%s); giving up: waiting %s would exceed the %s budget", req.Method, req.URL.Path, attempt, t.policy.MaxAttempts, reason, delay, t.policy.Budget)
+			return res, err
+		}
+
+		log.Printf("Jira %s %s: attempt %d/%d failed (%s); retrying in %s", req.Method, req.URL.Path, attempt, t.policy.MaxAttempts, reason, delay.Round(time.Millisecond))
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry reports whether the attempt failed in a way that can be
+// retried, why, and the delay requested by the server if any.
+func (t *retryTransport) shouldRetry(ctx context.Context, res *http.Response, err error) (string, time.Duration, bool) {
+	if err != nil {
+		if ctx.Err() != nil || !isTransient(err) {
+			return "", 0, false
+		}
+		return err.Error(), 0, true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return res.Status, parseRetryAfter(res.Header.Get("Retry-After"), t.now()), true
+	default:
+		return "", 0, false
+	}
+}
+
+// backoff returns the wait before the attempt following the given one:
+// exponential, capped, and with a random jitter of up to half the delay.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.policy.BaseDelay
+	for i := 1; i < attempt && delay < t.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, t.policy.MaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter returns the delay requested by a Retry-After header, in
+// seconds or as an HTTP date, or zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// isTransient reports whether the network error is likely to go away on
+// retry.
+func isTransient(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EPIPE):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	default:
+		return false
+	}
+}
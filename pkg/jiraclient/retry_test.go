@@ -0,0 +1,242 @@
+package jiraclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	Budget:      time.Minute,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+}
+
+// flakyServer fails the first requests with the given responses, then
+// answers 204. It records the body of every request.
+type flakyServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures []func(http.ResponseWriter)
+	bodies   []string
+}
+
+func newFlakyServer(t *testing.T, failures ...func(http.ResponseWriter)) *flakyServer {
+	t.Helper()
+	s := &flakyServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		var fail func(http.ResponseWriter)
+		if len(s.failures) > 0 {
+			fail, s.failures = s.failures[0], s.failures[1:]
+		}
+		s.mu.Unlock()
+
+		if fail != nil {
+			fail(w)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *flakyServer) Bodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func status(code int, header ...string) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(code)
+	}
+}
+
+// hangUp closes the connection without responding.
+func hangUp(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func TestRetryRewindsTheBody(t *testing.T) {
+	server := newFlakyServer(t,
+		status(http.StatusTooManyRequests),
+		status(http.StatusBadGateway),
+		hangUp,
+		status(http.StatusGatewayTimeout),
+	)
+	policy := testRetryPolicy
+	policy.MaxAttempts = 5
+	client, err := NewWithToken(server.URL, "bugwatcher@example.com", "token", WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := client.Issue.UpdateAssigneeWithContext(context.Background(), "OCPBUGS-1", &jira.User{AccountID: "acct-alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", res.StatusCode)
+	}
+
+	bodies := server.Bodies()
+	if len(bodies) != 5 {
+		t.Fatalf("expected 5 attempts, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if !strings.Contains(body, "acct-alice") {
+			t.Errorf("attempt %d: expected the request body, got %q", i+1, body)
+		}
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	for _, tc := range [...]struct {
+		name         string
+		policy       RetryPolicy
+		failures     []func(http.ResponseWriter)
+		wantStatus   int
+		wantAttempts int
+	}{
+		{
+			name:         "max attempts",
+			policy:       testRetryPolicy,
+			failures:     repeat(status(http.StatusServiceUnavailable), 10),
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 4,
+		},
+		{
+			name:         "budget exceeded by Retry-After",
+			policy:       testRetryPolicy,
+			failures:     []func(http.ResponseWriter){status(http.StatusTooManyRequests, "Retry-After", "3600")},
+			wantStatus:   http.StatusTooManyRequests,
+			wantAttempts: 1,
+		},
+		{
+			name:         "not retryable",
+			policy:       testRetryPolicy,
+			failures:     []func(http.ResponseWriter){status(http.StatusInternalServerError)},
+			wantStatus:   http.StatusInternalServerError,
+			wantAttempts: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFlakyServer(t, tc.failures...)
+			transport := newRetryTransport(http.DefaultTransport, tc.policy)
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, res.StatusCode)
+			}
+			if got := len(server.Bodies()); got != tc.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.wantAttempts, got)
+			}
+		})
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)
+	server := newFlakyServer(t,
+		status(http.StatusTooManyRequests, "Retry-After", "7"),
+		status(http.StatusServiceUnavailable, "Retry-After", now.Add(30*time.Second).Format(http.TimeFormat)),
+	)
+
+	var waits []time.Duration
+	transport := newRetryTransport(http.DefaultTransport, testRetryPolicy)
+	transport.now = func() time.Time { return now }
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	if len(waits) != 2 || waits[0] != 7*time.Second || waits[1] != 30*time.Second {
+		t.Errorf("expected to wait 7s then 30s, got %v", waits)
+	}
+}
+
+func TestRetryHonorsTheContext(t *testing.T) {
+	server := newFlakyServer(t, repeat(status(http.StatusServiceUnavailable), 10)...)
+	policy := testRetryPolicy
+	policy.BaseDelay, policy.MaxDelay = time.Hour, time.Hour
+	policy.Budget = 10 * time.Hour
+	transport := newRetryTransport(http.DefaultTransport, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+	if got := len(server.Bodies()); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := newRetryTransport(nil, RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second})
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 8 * time.Second, 10: 10 * time.Second} {
+		for range 20 {
+			if got := transport.backoff(attempt); got < want/2 || got > want {
+				t.Errorf("attempt %d: expected a delay between %s and %s, got %s", attempt, want/2, want, got)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"":                              0,
+		"0":                             0,
+		"120":                           2 * time.Minute,
+		"-5":                            0,
+		"Mon, 06 May 2024 12:01:30 GMT": 90 * time.Second,
+		"Mon, 06 May 2024 11:00:00 GMT": 0,
+		"soon":                          0,
+	} {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("%q: expected %s, got %s", value, want, got)
+		}
+	}
+}
+
+func repeat[T any](v T, n int) []T {
+	s := make([]T, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
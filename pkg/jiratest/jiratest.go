@@ -0,0 +1,619 @@
+// Package jiratest provides an in-memory Jira server, to test the commands
+// end to end.
+//
+// The server implements the subset of the Jira REST API used by bugwatcher:
+// the JQL search with nextPageToken paging, reading and updating issues,
+// assigning and commenting, remote links, users, and the metadata of the
+// custom fields and of their options. It is seeded from JSON fixtures, and
+// records the requests that change an issue.
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// DefaultPageSize is the number of issues per search page when the client does
+// not set maxResults.
+const DefaultPageSize = 50
+
+// DefaultFields are the custom fields of OCPBUGS read by bugwatcher. Every
+// server holds them.
+var DefaultFields = []Field{
+	{ID: "customfield_10785", Name: "Release Note Type", Options: []Option{
+		{ID: "12509", Value: "Bug Fix"},
+		{ID: "12510", Value: "Release Note Not Required"},
+	}},
+	{ID: "customfield_10783", Name: "Release Note Text"},
+	{ID: "customfield_10847", Name: "Release Blocker", Options: []Option{
+		{ID: "16772", Value: "Approved"},
+		{ID: "16773", Value: "Proposed"},
+		{ID: "16774", Value: "Rejected"},
+	}},
+	{ID: "customfield_10638", Name: "Test Coverage", Options: []Option{
+		{ID: "15875", Value: "+"},
+		{ID: "15876", Value: "-"},
+		{ID: "15877", Value: "?"},
+	}},
+	{ID: "customfield_10667", Name: "CVE ID"},
+	{ID: "customfield_10400", Name: "Need Info From"},
+	{ID: "customfield_10855", Name: "Target Version"},
+	{ID: "customfield_10840", Name: "Severity"},
+}
+
+// Fixture is the initial content of the server.
+type Fixture struct {
+	// Fields are the custom fields.
+	Fields []Field `json:"fields"`
+	// Issues are Jira issues, as returned by the API. Each needs an id and a
+	// key.
+	Issues []map[string]any `json:"issues"`
+	// RemoteLinks are the remote links of the issues, by issue key.
+	RemoteLinks map[string][]jira.RemoteLink `json:"remote_links"`
+	// Users are the Jira users.
+	Users []jira.User `json:"users"`
+}
+
+// Field is a custom field.
+type Field struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Options are the values of the select fields.
+	Options []Option `json:"options,omitempty"`
+}
+
+// Option is a value of a select field.
+type Option struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+// Mutation is a request that changed an issue.
+type Mutation struct {
+	// Method is the HTTP method, e.g. "PUT".
+	Method string
+	// Path is the request path, e.g. "/rest/api/2/issue/OCPBUGS-1/assignee".
+	Path string
+	// Issue is the key of the issue.
+	Issue string
+	// Body is the decoded request body.
+	Body map[string]any
+}
+
+// Server is an in-memory Jira server.
+type Server struct {
+	*httptest.Server
+
+	// Filter selects the issues returned by a search. When nil, searches
+	// return all the issues. The server does not interpret JQL.
+	Filter func(jql string, issue jira.Issue) bool
+
+	t testing.TB
+
+	mu          sync.Mutex
+	fields      []Field
+	issues      []map[string]any
+	remoteLinks map[string][]jira.RemoteLink
+	users       []jira.User
+	mutations   []Mutation
+	// updated is the last update time given to an issue.
+	updated time.Time
+}
+
+// TimeFormat is the format of the timestamps of Jira, e.g. the "updated"
+// field of the issues.
+const TimeFormat = "2006-01-02T15:04:05.000-0700"
+
+// tick returns the time of an update, strictly after the previous one so that
+// each update can be told apart.
+func (s *Server) tick() time.Time {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if !now.After(s.updated) {
+		now = s.updated.Add(time.Millisecond)
+	}
+	s.updated = now
+	return now
+}
+
+// NewServer starts a server holding DefaultFields and the content of the given
+// fixture files. The server is closed at the end of the test.
+func NewServer(t testing.TB, fixtures ...string) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:           t,
+		fields:      slices.Clone(DefaultFields),
+		remoteLinks: make(map[string][]jira.RemoteLink),
+	}
+	for _, path := range fixtures {
+		if err := s.LoadFixture(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/{version}/search/jql", s.search)
+	mux.HandleFunc("GET /rest/api/{version}/issue/{key}", s.getIssue)
+	mux.HandleFunc("PUT /rest/api/{version}/issue/{key}", s.updateIssue)
+	mux.HandleFunc("PUT /rest/api/{version}/issue/{key}/assignee", s.assign)
+	mux.HandleFunc("POST /rest/api/{version}/issue/{key}/comment", s.comment)
+	mux.HandleFunc("GET /rest/api/{version}/issue/{key}/remotelink", s.getRemoteLinks)
+	mux.HandleFunc("GET /rest/api/{version}/user", s.getUser)
+	mux.HandleFunc("GET /rest/api/{version}/field", s.getFields)
+	mux.HandleFunc("GET /rest/api/{version}/field/{id}/context", s.getFieldContexts)
+	mux.HandleFunc("GET /rest/api/{version}/field/{id}/context/{context}/option", s.getFieldOptions)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// LoadFixture adds the content of the JSON fixture file to the server.
+func (s *Server) LoadFixture(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading the fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(b, &fixture); err != nil {
+		return fmt.Errorf("error decoding the fixture %q: %w", path, err)
+	}
+	s.Seed(fixture)
+	return nil
+}
+
+// Seed adds the content of the fixture to the server.
+func (s *Server) Seed(fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fields = append(s.fields, fixture.Fields...)
+	for _, issue := range fixture.Issues {
+		if _, ok := issue["fields"].(map[string]any); !ok {
+			issue["fields"] = make(map[string]any)
+		}
+		s.issues = append(s.issues, issue)
+	}
+	for key, links := range fixture.RemoteLinks {
+		s.remoteLinks[key] = append(s.remoteLinks[key], links...)
+	}
+	s.users = append(s.users, fixture.Users...)
+}
+
+// Issue returns the current state of the issue with the given key or ID.
+func (s *Server) Issue(key string) (jira.Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw := s.find(key)
+	if raw == nil {
+		return jira.Issue{}, false
+	}
+	return decode(raw), true
+}
+
+// Mutations returns the requests that changed an issue, in order.
+func (s *Server) Mutations() []Mutation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.mutations)
+}
+
+// find returns the issue with the given key or ID, or nil.
+func (s *Server) find(key string) map[string]any {
+	for _, issue := range s.issues {
+		if issue["key"] == key || issue["id"] == key {
+			return issue
+		}
+	}
+	return nil
+}
+
+// decode converts the raw issue to the type of the Jira client.
+func decode(raw map[string]any) jira.Issue {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		panic(err)
+	}
+	var issue jira.Issue
+	if err := json.Unmarshal(b, &issue); err != nil {
+		panic(err)
+	}
+	return issue
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.t.Errorf("jiratest: error encoding the response: %v", err)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	s.writeJSON(w, status, map[string]any{
+		"errorMessages": []string{fmt.Sprintf(format, args...)},
+	})
+}
+
+// readBody decodes the JSON request body.
+func readBody(r *http.Request) (map[string]any, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	body := make(map[string]any)
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &body); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	jql := query.Get("jql")
+
+	pageSize := DefaultPageSize
+	if maxResults := query.Get("maxResults"); maxResults != "" {
+		n, err := strconv.Atoi(maxResults)
+		if err != nil || n < 1 {
+			s.writeError(w, http.StatusBadRequest, "invalid maxResults %q", maxResults)
+			return
+		}
+		pageSize = n
+	}
+
+	var start int
+	if token := query.Get("nextPageToken"); token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid nextPageToken %q", token)
+			return
+		}
+		start = n
+	}
+
+	// The page shares the maps of the issues, which are encoded before
+	// releasing the lock.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matches []map[string]any
+	for _, raw := range s.issues {
+		if s.Filter == nil || s.Filter(jql, decode(raw)) {
+			matches = append(matches, raw)
+		}
+	}
+
+	end := min(start+pageSize, len(matches))
+	start = min(start, end)
+	page := matches[start:end]
+	if fields := query.Get("fields"); fields != "" {
+		page = project(page, strings.Split(fields, ","))
+	}
+	response := map[string]any{
+		"issues": page,
+		"isLast": end == len(matches),
+	}
+	if end < len(matches) {
+		response["nextPageToken"] = strconv.Itoa(end)
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// project returns the issues with only the requested fields, like Jira does.
+// "*all" and "*navigable" return all the fields.
+func project(issues []map[string]any, fields []string) []map[string]any {
+	if slices.Contains(fields, "*all") || slices.Contains(fields, "*navigable") {
+		return issues
+	}
+	projected := make([]map[string]any, len(issues))
+	for i, raw := range issues {
+		issue := make(map[string]any, len(raw))
+		for k, v := range raw {
+			if k != "fields" {
+				issue[k] = v
+			}
+		}
+		all, _ := raw["fields"].(map[string]any)
+		kept := make(map[string]any, len(fields))
+		for _, field := range fields {
+			if v, ok := all[field]; ok {
+				kept[field] = v
+			}
+		}
+		issue["fields"] = kept
+		projected[i] = issue
+	}
+	return projected
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issue := s.find(r.PathValue("key"))
+	if issue == nil {
+		s.writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, issue)
+}
+
+// mutate applies change to the issue of the request, and records the
+// mutation.
+func (s *Server) mutate(w http.ResponseWriter, r *http.Request, change func(issue, body map[string]any) error) (map[string]any, bool) {
+	body, err := readBody(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issue := s.find(r.PathValue("key"))
+	if issue == nil {
+		s.writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return nil, false
+	}
+	if err := change(issue, body); err != nil {
+		s.writeError(w, http.StatusBadRequest, "%v", err)
+		return nil, false
+	}
+	issue["fields"].(map[string]any)["updated"] = s.tick().Format(TimeFormat)
+
+	s.mutations = append(s.mutations, Mutation{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Issue:  issue["key"].(string),
+		Body:   body,
+	})
+	return issue, true
+}
+
+func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request) {
+	_, ok := s.mutate(w, r, func(issue, body map[string]any) error {
+		fields := issue["fields"].(map[string]any)
+
+		if set, ok := body["fields"].(map[string]any); ok {
+			for name, value := range set {
+				fields[name] = s.resolveOptions(name, value)
+			}
+		}
+
+		update, _ := body["update"].(map[string]any)
+		for name, operations := range update {
+			operations, ok := operations.([]any)
+			if !ok {
+				return fmt.Errorf("update of %q: expected a list of operations", name)
+			}
+			for _, operation := range operations {
+				operation, ok := operation.(map[string]any)
+				if !ok {
+					return fmt.Errorf("update of %q: expected an operation object", name)
+				}
+				for verb, value := range operation {
+					value = s.resolveOptions(name, value)
+					switch verb {
+					case "set":
+						fields[name] = value
+					case "add":
+						values, _ := fields[name].([]any)
+						fields[name] = append(values, value)
+					case "remove":
+						values, _ := fields[name].([]any)
+						fields[name] = slices.DeleteFunc(slices.Clone(values), func(v any) bool {
+							return reflect.DeepEqual(v, value)
+						})
+					default:
+						return fmt.Errorf("update of %q: unknown operation %q", name, verb)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if ok {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// resolveOptions adds the option IDs to the values of select fields set by
+// value, the way Jira returns them.
+func (s *Server) resolveOptions(fieldID string, value any) any {
+	var options []Option
+	for _, field := range s.fields {
+		if field.ID == fieldID {
+			options = field.Options
+		}
+	}
+	if len(options) == 0 {
+		return value
+	}
+
+	resolve := func(v any) any {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		if _, ok := m["id"]; ok {
+			return m
+		}
+		for _, option := range options {
+			if option.Value == m["value"] {
+				return map[string]any{"id": option.ID, "value": option.Value}
+			}
+		}
+		return m
+	}
+
+	if values, ok := value.([]any); ok {
+		resolved := make([]any, len(values))
+		for i, v := range values {
+			resolved[i] = resolve(v)
+		}
+		return resolved
+	}
+	return resolve(value)
+}
+
+func (s *Server) assign(w http.ResponseWriter, r *http.Request) {
+	_, ok := s.mutate(w, r, func(issue, body map[string]any) error {
+		fields := issue["fields"].(map[string]any)
+		accountID, _ := body["accountId"].(string)
+		if accountID == "" {
+			fields["assignee"] = nil
+			return nil
+		}
+		fields["assignee"] = map[string]any{"accountId": accountID}
+		for _, user := range s.users {
+			if user.AccountID == accountID {
+				fields["assignee"] = map[string]any{
+					"accountId":    accountID,
+					"displayName":  user.DisplayName,
+					"emailAddress": user.EmailAddress,
+				}
+			}
+		}
+		return nil
+	})
+	if ok {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) comment(w http.ResponseWriter, r *http.Request) {
+	var comment map[string]any
+	_, ok := s.mutate(w, r, func(issue, body map[string]any) error {
+		if _, ok := body["body"].(string); !ok {
+			return fmt.Errorf("comment body: expected a string")
+		}
+		fields := issue["fields"].(map[string]any)
+		comments, _ := fields["comment"].(map[string]any)
+		if comments == nil {
+			comments = map[string]any{"comments": []any{}}
+			fields["comment"] = comments
+		}
+		list, _ := comments["comments"].([]any)
+		comment = map[string]any{
+			"id":   strconv.Itoa(len(s.mutations) + 1),
+			"body": body["body"],
+		}
+		comments["comments"] = append(list, comment)
+		comments["total"] = len(list) + 1
+		return nil
+	})
+	if ok {
+		s.writeJSON(w, http.StatusCreated, comment)
+	}
+}
+
+func (s *Server) getRemoteLinks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issue := s.find(r.PathValue("key"))
+	if issue == nil {
+		s.writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	links := s.remoteLinks[issue["key"].(string)]
+	if links == nil {
+		links = []jira.RemoteLink{}
+	}
+	s.writeJSON(w, http.StatusOK, links)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accountID := r.URL.Query().Get("accountId")
+	for _, user := range s.users {
+		if user.AccountID == accountID {
+			s.writeJSON(w, http.StatusOK, user)
+			return
+		}
+	}
+	s.writeError(w, http.StatusNotFound, "The user with account ID '%s' does not exist", accountID)
+}
+
+func (s *Server) getFields(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fields := make([]jira.Field, 0, len(s.fields))
+	for _, field := range s.fields {
+		fields = append(fields, jira.Field{ID: field.ID, Key: field.ID, Name: field.Name, Custom: true})
+	}
+	s.writeJSON(w, http.StatusOK, fields)
+}
+
+// field returns the custom field with the ID of the request path.
+func (s *Server) field(w http.ResponseWriter, r *http.Request) (Field, bool) {
+	for _, field := range s.fields {
+		if field.ID == r.PathValue("id") {
+			return field, true
+		}
+	}
+	s.writeError(w, http.StatusNotFound, "The custom field was not found.")
+	return Field{}, false
+}
+
+// getFieldContexts returns a single context per field, holding all its
+// options.
+func (s *Server) getFieldContexts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.field(w, r); !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"values": []map[string]any{{"id": "1", "name": "Default Configuration Scheme"}},
+		"isLast": true,
+	})
+}
+
+func (s *Server) getFieldOptions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	field, ok := s.field(w, r)
+	if !ok {
+		return
+	}
+	if r.PathValue("context") != "1" {
+		s.writeError(w, http.StatusNotFound, "The context was not found.")
+		return
+	}
+
+	var start int
+	if startAt := r.URL.Query().Get("startAt"); startAt != "" {
+		start, _ = strconv.Atoi(startAt)
+	}
+	start = min(max(start, 0), len(field.Options))
+	values := field.Options[start:]
+	if values == nil {
+		values = []Option{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"values": values,
+		"isLast": true,
+	})
+}
@@ -0,0 +1,90 @@
+package mutation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// checkResponse returns an error describing the failure, if the request
+// failed or if Jira responded with an unexpected status code. It consumes and
+// closes the response body.
+func checkResponse(res *jira.Response, err error, what string) error {
+	if err != nil && res == nil {
+		// we only error out early if there's no response to work with
+		return fmt.Errorf("error while %s: %w", what, err)
+	}
+
+	// we don't check errors since this is best effort
+	bodyBytes, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusAccepted, http.StatusCreated:
+	default:
+		return fmt.Errorf("unexpected status code %q from Jira while %s: err=%w body=%s", res.Status, what, err, bodyBytes)
+	}
+
+	return nil
+}
+
+// Assign sets the assignee of the issue.
+func Assign(ctx context.Context, jiraClient *jira.Client, issue jira.Issue, assigneeAccountID string) error {
+	return Apply(Record{
+		Action: "jira.assign",
+		Target: issue.Key,
+		Data:   map[string]string{"accountId": assigneeAccountID},
+	}, func() error {
+		res, err := jiraClient.Issue.UpdateAssigneeWithContext(ctx, issue.ID, &jira.User{AccountID: assigneeAccountID})
+		return checkResponse(res, err, "assigning bug "+issue.Key)
+	})
+}
+
+// Update applies the given update payload to the issue. See
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issues/#api-rest-api-2-issue-issueidorkey-put
+func Update(ctx context.Context, jiraClient *jira.Client, issue jira.Issue, updates map[string]any) error {
+	return Apply(Record{
+		Action: "jira.update",
+		Target: issue.Key,
+		Data:   updates,
+	}, func() error {
+		res, err := jiraClient.Issue.UpdateIssueWithContext(ctx, issue.ID, updates)
+		return checkResponse(res, err, "updating bug "+issue.Key)
+	})
+}
+
+// RemoveLabels removes the given labels from the issue.
+func RemoveLabels(ctx context.Context, jiraClient *jira.Client, issue jira.Issue, labels ...string) error {
+	operations := make([]map[string]string, len(labels))
+	for i, label := range labels {
+		operations[i] = map[string]string{"remove": label}
+	}
+
+	return Apply(Record{
+		Action: "jira.remove_labels",
+		Target: issue.Key,
+		Data:   labels,
+	}, func() error {
+		res, err := jiraClient.Issue.UpdateIssueWithContext(ctx, issue.ID, map[string]any{
+			"update": map[string]any{
+				"labels": operations,
+			},
+		})
+		return checkResponse(res, err, "removing labels from bug "+issue.Key)
+	})
+}
+
+// Comment adds a comment to the issue.
+func Comment(ctx context.Context, jiraClient *jira.Client, issue jira.Issue, body string) error {
+	return Apply(Record{
+		Action: "jira.comment",
+		Target: issue.Key,
+		Data:   body,
+	}, func() error {
+		_, res, err := jiraClient.Issue.AddCommentWithContext(ctx, issue.ID, &jira.Comment{Body: body})
+		return checkResponse(res, err, "commenting bug "+issue.Key)
+	})
+}
@@ -0,0 +1,76 @@
+// Package mutation is the single path through which bugwatcher changes the
+// outside world: Jira updates and Slack messages. In dry-run mode, each
+// mutation is printed as a JSON record instead of being applied.
+package mutation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Record describes a mutation.
+type Record struct {
+	// Action is the kind of mutation, e.g. "jira.assign".
+	Action string `json:"action"`
+	// Target is what the mutation applies to, e.g. an issue key.
+	Target string `json:"target"`
+	// Data is the payload of the mutation.
+	Data any `json:"data,omitempty"`
+}
+
+var (
+	mu     sync.Mutex
+	dryRun bool
+	output io.Writer = os.Stdout
+)
+
+// SetDryRun enables or disables the dry-run mode.
+func SetDryRun(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dryRun = enabled
+}
+
+// DryRun returns true if the dry-run mode is enabled.
+func DryRun() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return dryRun
+}
+
+// SetOutput sets where the dry-run records are printed. Defaults to standard
+// output.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	output = w
+}
+
+// Apply runs apply, unless the dry-run mode is enabled. In that case, the
+// record is printed and apply is not called.
+func Apply(record Record, apply func() error) error {
+	mu.Lock()
+	if !dryRun {
+		mu.Unlock()
+		return apply()
+	}
+	defer mu.Unlock()
+
+	b, err := json.Marshal(struct {
+		WouldDo bool `json:"would_do"`
+		Record
+	}{true, record})
+	if err != nil {
+		return fmt.Errorf("error encoding the dry-run record: %w", err)
+	}
+	if _, err := fmt.Fprintf(output, "%s\n", b); err != nil {
+		return fmt.Errorf("error printing the dry-run record: %w", err)
+	}
+	return nil
+}
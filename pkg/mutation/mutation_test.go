@@ -0,0 +1,91 @@
+package mutation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// setup sets the dry-run mode and captures the records, restoring the
+// defaults at the end of the test.
+func setup(t *testing.T, enabled bool) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	SetDryRun(enabled)
+	SetOutput(&buf)
+	t.Cleanup(func() {
+		SetDryRun(false)
+		SetOutput(os.Stdout)
+	})
+	return &buf
+}
+
+func TestApplyDryRun(t *testing.T) {
+	buf := setup(t, true)
+
+	var called bool
+	err := Apply(Record{Action: "jira.assign", Target: "OCPBUGS-1", Data: map[string]string{"accountId": "acct-1"}}, func() error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Errorf("expected the mutation not to be applied in dry-run mode")
+	}
+	if got, want := buf.String(), `{"would_do":true,"action":"jira.assign","target":"OCPBUGS-1","data":{"accountId":"acct-1"}}`+"\n"; got != want {
+		t.Errorf("expected the record %q, got %q", want, got)
+	}
+}
+
+func TestApply(t *testing.T) {
+	buf := setup(t, false)
+
+	var called bool
+	failure := errors.New("failure")
+	err := Apply(Record{Action: "jira.assign", Target: "OCPBUGS-1"}, func() error {
+		called = true
+		return failure
+	})
+	if !called {
+		t.Errorf("expected the mutation to be applied")
+	}
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the error of the mutation, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no record, got %q", buf.String())
+	}
+}
+
+func TestCommentDryRun(t *testing.T) {
+	buf := setup(t, true)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+	jiraClient, err := jira.NewClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Comment(context.Background(), jiraClient, jira.Issue{ID: "1", Key: "OCPBUGS-1"}, "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no request to Jira, got %d", requests)
+	}
+	if got, want := buf.String(), `{"would_do":true,"action":"jira.comment","target":"OCPBUGS-1","data":"hello"}`+"\n"; got != want {
+		t.Errorf("expected the record %q, got %q", want, got)
+	}
+}
@@ -1,19 +1,65 @@
 package query
 
-const JiraBaseURL = "https://redhat.atlassian.net/"
-
-const ShiftStack = `project = "OpenShift Bugs"
-	AND (
-		component in (
-			"Installer / OpenShift on OpenStack",
-			"Storage / OpenStack CSI Drivers",
-			"Cloud Compute / OpenStack Provider",
-			"Machine Config Operator / platform-openstack",
-			"Networking / kuryr",
-			"Test Framework / OpenStack",
-			"HyperShift / OpenStack"
-		)
-	)
-	AND labels != "bugwatcher-ignore"
-	AND labels != "SecurityTracking"
-`
+import (
+	"strings"
+	"time"
+)
+
+// Scope defines the set of bugs looked after by bugwatcher.
+type Scope struct {
+	Project        string
+	Components     []string
+	ExcludedLabels []string
+}
+
+// JQL returns the query selecting the bugs in scope. Further conditions can be
+// appended, starting with "AND".
+func (s Scope) JQL() string {
+	var jql strings.Builder
+	jql.WriteString("project = " + Quote(s.Project) + "\n")
+	jql.WriteString("\tAND (\n\t\tcomponent in (\n")
+	for i, component := range s.Components {
+		jql.WriteString("\t\t\t" + Quote(component))
+		if i < len(s.Components)-1 {
+			jql.WriteByte(',')
+		}
+		jql.WriteByte('\n')
+	}
+	jql.WriteString("\t\t)\n\t)\n")
+	for _, label := range s.ExcludedLabels {
+		jql.WriteString("\tAND labels != " + Quote(label) + "\n")
+	}
+	return jql.String()
+}
+
+// Quote returns s as a JQL string literal.
+func Quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// IssueURL returns the URL of the web page of the issue.
+func IssueURL(jiraBaseURL, key string) string {
+	return strings.TrimSuffix(jiraBaseURL, "/") + "/browse/" + key
+}
+
+// SnoozeDays is the number of days during which a snoozed bug is left out of
+// the triage reminders.
+const SnoozeDays = 2
+
+// SnoozeLabel returns the label snoozing a bug until the given day, in UTC,
+// e.g. "bugwatcher-snoozed-until-2026-10-20". The reminders resume on that
+// day.
+func SnoozeLabel(until time.Time) string {
+	return "bugwatcher-snoozed-until-" + until.UTC().Format(time.DateOnly)
+}
+
+// NotSnoozed returns the condition leaving out the bugs that are snoozed at
+// now, starting with "AND". The labels of the past snoozes do not match, so
+// they need not be removed.
+func NotSnoozed(now time.Time) string {
+	labels := make([]string, SnoozeDays)
+	for i := range labels {
+		labels[i] = Quote(SnoozeLabel(now.AddDate(0, 0, i+1)))
+	}
+	return `AND (labels not in (` + strings.Join(labels, ", ") + `) OR labels is EMPTY)`
+}
@@ -0,0 +1,20 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotSnoozed(t *testing.T) {
+	now := time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+
+	if got, want := SnoozeLabel(now.AddDate(0, 0, SnoozeDays)), "bugwatcher-snoozed-until-2026-10-21"; got != want {
+		t.Errorf("expected the label %q, got %q", want, got)
+	}
+
+	// A bug snoozed on the 19th (UTC) is left out on the 19th and the 20th,
+	// and reminded again on the 21st.
+	if got, want := NotSnoozed(now), `AND (labels not in ("bugwatcher-snoozed-until-2026-10-20", "bugwatcher-snoozed-until-2026-10-21") OR labels is EMPTY)`; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
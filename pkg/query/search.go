@@ -2,43 +2,135 @@ package query
 
 import (
 	"context"
+	"fmt"
+	"iter"
 	"log"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 
 	jira "github.com/andygrunwald/go-jira"
 )
 
-func SearchIssues(ctx context.Context, client *jira.Client, searchString string) <-chan jira.Issue {
-	issueCh := make(chan jira.Issue)
+// pageSize is the number of issues fetched by each search request.
+const pageSize = 100
 
-	go func() {
-		opt := &jira.SearchOptionsV2{MaxResults: 100, Fields: []string{"*all"}}
+// IDs of the Jira system fields read by bugwatcher.
+const (
+	FieldAssignee   = "assignee"
+	FieldComment    = "comment"
+	FieldComponents = "components"
+	FieldCreated    = "created"
+	FieldIssueLinks = "issuelinks"
+	FieldIssueType  = "issuetype"
+	FieldLabels     = "labels"
+	FieldPriority   = "priority"
+	FieldReporter   = "reporter"
+	FieldResolution = "resolution"
+	FieldStatus     = "status"
+	FieldSummary    = "summary"
+)
+
+// Cache serves the search results from a local copy of the issues.
+type Cache interface {
+	Search(ctx context.Context, client *jira.Client, jql string) iter.Seq2[jira.Issue, error]
+}
+
+var (
+	mu    sync.Mutex
+	cache Cache
+)
+
+// SetCache makes SearchIssues serve the issues from the cache. A nil cache
+// disables caching.
+func SetCache(c Cache) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cache = c
+}
+
+// SearchOption customizes the issues returned by SearchIssues.
+type SearchOption func(*jira.SearchOptionsV2)
+
+// WithFields restricts the fields returned for each issue to the given field
+// IDs, system fields or custom fields. The ID and the key of the issues are
+// always returned. Without this option, all the fields are returned.
+func WithFields(ids ...string) SearchOption {
+	return func(opt *jira.SearchOptionsV2) {
+		opt.Fields = opt.Fields[:0:0]
+		for _, id := range ids {
+			if !slices.Contains(opt.Fields, id) {
+				opt.Fields = append(opt.Fields, id)
+			}
+		}
+	}
+}
+
+// WithExpand requests additional information for each issue, e.g.
+// "changelog" or "renderedFields".
+func WithExpand(expand ...string) SearchOption {
+	return func(opt *jira.SearchOptionsV2) {
+		opt.Expand = strings.Join(expand, ",")
+	}
+}
+
+// SearchIssues returns an iterator over the issues matching the JQL query,
+// fetched page by page as the iteration progresses.
+//
+// If fetching a page fails, or if the context is done, the iterator yields
+// the error with a zero issue and stops: the issues yielded before are valid,
+// but the results are incomplete. Stopping the iteration early stops the
+// search.
+//
+// If a cache is set, the issues are served from it with all their fields,
+// unless expands are requested.
+func SearchIssues(ctx context.Context, client *jira.Client, jql string, opts ...SearchOption) iter.Seq2[jira.Issue, error] {
+	opt := &jira.SearchOptionsV2{MaxResults: pageSize, Fields: []string{"*all"}}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	mu.Lock()
+	c := cache
+	mu.Unlock()
+	if c != nil && opt.Expand == "" {
+		return c.Search(ctx, client, jql)
+	}
+
+	return func(yield func(jira.Issue, error) bool) {
+		opt := *opt
 		for {
-			issues, res, err := client.Issue.SearchV2JQLWithContext(ctx, searchString, opt)
+			if err := ctx.Err(); err != nil {
+				yield(jira.Issue{}, err)
+				return
+			}
+
+			issues, res, err := client.Issue.SearchV2JQLWithContext(ctx, jql, &opt)
 			if err != nil {
-				log.Fatalf("error fetching issues: %v", err)
+				yield(jira.Issue{}, fmt.Errorf("error fetching issues: %w", err))
 				return
 			}
 			switch res.StatusCode {
 			case http.StatusOK, http.StatusNoContent, http.StatusAccepted:
 			default:
-				log.Printf("unexpected status code %q while fetching issues", res.Status)
+				yield(jira.Issue{}, fmt.Errorf("unexpected status code %q while fetching issues", res.Status))
 				return
 			}
 
 			log.Printf("Incoming batch of %d issues", len(issues))
 
 			for _, issue := range issues {
-				issueCh <- issue
+				if !yield(issue, nil) {
+					return
+				}
 			}
 
 			if res.IsLast || res.NextPageToken == "" {
-				break
+				return
 			}
 			opt.NextPageToken = res.NextPageToken
 		}
-		close(issueCh)
-	}()
-
-	return issueCh
+	}
 }
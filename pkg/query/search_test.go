@@ -0,0 +1,158 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// searchServer serves total issues in pages, failing from the page failAt on
+// if it is not zero. It counts the requests.
+func searchServer(t *testing.T, total, failAt int) (*jira.Client, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := int(requests.Add(1))
+		if failAt > 0 && page >= failAt {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errorMessages":["bad JQL"]}`)
+			return
+		}
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("nextPageToken"))
+		end := min(start+pageSize, total)
+		var issues []string
+		for i := start; i < end; i++ {
+			issues = append(issues, fmt.Sprintf(`{"key":"OCPBUGS-%d"}`, i))
+		}
+		next := ""
+		if end < total {
+			next = strconv.Itoa(end)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"issues":[%s],"isLast":%t,"nextPageToken":%q}`, strings.Join(issues, ","), next == "", next)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := jira.NewClient(nil, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &requests
+}
+
+func TestSearchIssues(t *testing.T) {
+	client, requests := searchServer(t, 250, 0)
+
+	var keys []string
+	for issue, err := range SearchIssues(context.Background(), client, "project = OCPBUGS") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		keys = append(keys, issue.Key)
+	}
+	if len(keys) != 250 || keys[249] != "OCPBUGS-249" {
+		t.Errorf("expected the 250 issues, got %d", len(keys))
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected 3 pages, got %d", got)
+	}
+}
+
+func TestSearchIssuesError(t *testing.T) {
+	client, _ := searchServer(t, 250, 2)
+
+	var found, errs int
+	for _, err := range SearchIssues(context.Background(), client, "project = OCPBUGS") {
+		if err != nil {
+			errs++
+			continue
+		}
+		found++
+	}
+	if found != pageSize || errs != 1 {
+		t.Errorf("expected the first page then one error, got %d issues and %d errors", found, errs)
+	}
+}
+
+func TestSearchIssuesStop(t *testing.T) {
+	client, requests := searchServer(t, 250, 0)
+
+	for issue := range SearchIssues(context.Background(), client, "project = OCPBUGS") {
+		if issue.Key == "OCPBUGS-10" {
+			break
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("expected the search to stop after the first page, got %d requests", got)
+	}
+}
+
+func TestSearchIssuesCancel(t *testing.T) {
+	client, requests := searchServer(t, 250, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var found int
+	var lastErr error
+	for _, err := range SearchIssues(ctx, client, "project = OCPBUGS") {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		found++
+		if found == pageSize {
+			cancel()
+		}
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("expected the context error, got %v", lastErr)
+	}
+	if found != pageSize || requests.Load() != 1 {
+		t.Errorf("expected the search to stop after the first page, got %d issues in %d requests", found, requests.Load())
+	}
+}
+
+func TestSearchIssuesOptions(t *testing.T) {
+	for _, tc := range [...]struct {
+		name       string
+		opts       []SearchOption
+		wantFields string
+		wantExpand string
+	}{
+		{"default", nil, "*all", ""},
+		{"fields", []SearchOption{WithFields(FieldAssignee, "customfield_10785", FieldAssignee)}, "assignee,customfield_10785", ""},
+		{"expand", []SearchOption{WithFields(FieldStatus), WithExpand("changelog", "renderedFields")}, "status", "changelog,renderedFields"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var fields, expand string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fields, expand = r.URL.Query().Get("fields"), r.URL.Query().Get("expand")
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"issues":[],"isLast":true}`)
+			}))
+			defer server.Close()
+			client, err := jira.NewClient(nil, server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, err := range SearchIssues(context.Background(), client, "project = OCPBUGS", tc.opts...) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if fields != tc.wantFields || expand != tc.wantExpand {
+				t.Errorf("expected fields=%q expand=%q, got fields=%q expand=%q", tc.wantFields, tc.wantExpand, fields, expand)
+			}
+		})
+	}
+}
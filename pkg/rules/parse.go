@@ -0,0 +1,319 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The grammar of the expressions, from the lowest to the highest precedence:
+//
+//	expr   = or [ "unless" or ]
+//	or     = and { "or" and }
+//	and    = not { "and" not }
+//	not    = "not" not | "(" expr ")" | cmp
+//	cmp    = field ( "=" | "!=" ) value
+//	       | field [ "not" ] "in" "(" value { "," value } ")"
+//	       | field "is" [ "not" ] "empty"
+//	value  = quoted string | word { word }
+//
+// Unquoted values span several words, e.g. Not a Bug, up to the next keyword
+// or punctuation.
+
+var keywords = map[string]bool{
+	"and":    true,
+	"or":     true,
+	"not":    true,
+	"unless": true,
+	"in":     true,
+	"is":     true,
+	"empty":  true,
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return fmt.Sprintf("%q at offset %d", t.text, t.pos)
+	}
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && t.text == keyword
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, token{tokenPunct, string(c), i})
+			i++
+		case c == '!':
+			if i+1 >= len(s) || s[i+1] != '=' {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{tokenPunct, "!=", i})
+			i += 2
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{tokenString, b.String(), i})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n\r(),=!\"", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{tokenWord, s[i:j], i})
+			i = j
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.text != text || t.kind == tokenString {
+		return fmt.Errorf("expected %q, found %s", text, t)
+	}
+	return nil
+}
+
+// parse returns the syntax tree of the expression.
+func parse(s string) (node, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+	return n, nil
+}
+
+func (p *parser) parseExpr() (node, error) {
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peek().is("unless") {
+		return cond, nil
+	}
+	p.next()
+	except, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return unlessNode{cond, except}, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	switch t := p.peek(); {
+	case t.is("not"):
+		p.next()
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	case t.kind == tokenPunct && t.text == "(":
+		p.next()
+		n, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return n, nil
+	default:
+		return p.parseCmp()
+	}
+}
+
+func (p *parser) parseCmp() (node, error) {
+	t := p.next()
+	if t.kind != tokenWord || keywords[t.text] {
+		return nil, fmt.Errorf("expected a field name, found %s", t)
+	}
+	field := strings.ToLower(t.text)
+	if _, ok := fieldsByName[field]; !ok {
+		return nil, fmt.Errorf("unknown field %q", t.text)
+	}
+
+	switch t := p.next(); {
+	case t.kind == tokenPunct && (t.text == "=" || t.text == "!="):
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		var n node = inNode{field, []string{value}}
+		if t.text == "!=" {
+			n = notNode{n}
+		}
+		return n, nil
+
+	case t.is("in"):
+		return p.parseIn(field)
+
+	case t.is("not"):
+		if err := p.expect("in"); err != nil {
+			return nil, err
+		}
+		n, err := p.parseIn(field)
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+
+	case t.is("is"):
+		negate := p.peek().is("not")
+		if negate {
+			p.next()
+		}
+		if err := p.expect("empty"); err != nil {
+			return nil, err
+		}
+		var n node = emptyNode{field}
+		if negate {
+			n = notNode{n}
+		}
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("expected an operator after %q, found %s", field, t)
+	}
+}
+
+func (p *parser) parseIn(field string) (node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		if t.kind == tokenPunct && t.text == ")" {
+			return inNode{field, values}, nil
+		}
+		if t.kind != tokenPunct || t.text != "," {
+			return nil, fmt.Errorf("expected \",\" or \")\", found %s", t)
+		}
+	}
+}
+
+func (p *parser) parseValue() (string, error) {
+	if t := p.peek(); t.kind == tokenString {
+		p.next()
+		return t.text, nil
+	}
+
+	var words []string
+	for t := p.peek(); t.kind == tokenWord && !keywords[t.text]; t = p.peek() {
+		words = append(words, p.next().text)
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("expected a value, found %s", p.peek())
+	}
+	return strings.Join(words, " "), nil
+}
+
+// isIdentifier reports whether s can be used as a rule name.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,391 @@
+// Package rules evaluates the triage rules declared in the configuration
+// file.
+//
+// A rule is a condition that every bug in its scope must satisfy, written as
+// an expression over the fields of the issue, e.g.:
+//
+//	priority is not empty and priority != Undefined unless resolution in (Duplicate, Not a Bug)
+//
+// Comparisons are case-insensitive. On multi-valued fields such as labels,
+// "=" and "in" hold when any of the values matches.
+package rules
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+	"gopkg.in/yaml.v3"
+)
+
+// Commands that evaluate rules.
+const (
+	ScopePosttriage = "posttriage"
+	ScopeDoctext    = "doctext"
+)
+
+var scopes = []string{ScopePosttriage, ScopeDoctext}
+
+// Severity defines the consequence of a rule not being satisfied.
+type Severity string
+
+const (
+	// SeverityError rules are enforced like the built-in checks of the
+	// command.
+	SeverityError Severity = "error"
+	// SeverityWarning rules are only logged.
+	SeverityWarning Severity = "warning"
+)
+
+// Rule is a triage requirement.
+type Rule struct {
+	// Name identifies the rule in the logs and in the Jira comments.
+	Name string `yaml:"name"`
+	// Expr is the condition that the bugs must satisfy.
+	Expr string `yaml:"expr"`
+	// Message explains what is missing when the condition is not met.
+	Message string `yaml:"message"`
+	// Severity defaults to SeverityError.
+	Severity Severity `yaml:"severity"`
+	// Scope lists the commands running the rule.
+	Scope []string `yaml:"scope"`
+
+	cond node
+}
+
+// ruleKeys are the YAML keys of Rule.
+var ruleKeys = []string{"name", "expr", "message", "severity", "scope"}
+
+// UnmarshalYAML decodes the rule and parses its expression. Unknown keys are
+// rejected: Node.Decode does not honour the KnownFields setting of the
+// decoder, and a misspelled key would otherwise be silently ignored.
+func (r *Rule) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i < len(value.Content); i += 2 {
+			if key := value.Content[i]; !slices.Contains(ruleKeys, key.Value) {
+				return fmt.Errorf("line %d: field %s not found in type rules.Rule", key.Line, key.Value)
+			}
+		}
+	}
+
+	type plain Rule
+	if err := value.Decode((*plain)(r)); err != nil {
+		return err
+	}
+	if r.Severity == "" {
+		r.Severity = SeverityError
+	}
+
+	cond, err := parse(r.Expr)
+	if err != nil {
+		return fmt.Errorf("line %d: rule %q: %w", value.Line, r.Name, err)
+	}
+	r.cond = cond
+	return nil
+}
+
+// Validate returns an error if the rule is incomplete.
+func (r Rule) Validate() error {
+	if !isIdentifier(r.Name) {
+		return fmt.Errorf("%q is not a valid rule name: use letters, digits, '-' and '_'", r.Name)
+	}
+	if r.cond == nil {
+		return fmt.Errorf("rule %q: missing expression", r.Name)
+	}
+	if r.Message == "" {
+		return fmt.Errorf("rule %q: missing message", r.Name)
+	}
+	if r.Severity != SeverityError && r.Severity != SeverityWarning {
+		return fmt.Errorf("rule %q: unknown severity %q: expected %q or %q", r.Name, r.Severity, SeverityError, SeverityWarning)
+	}
+	if len(r.Scope) == 0 {
+		return fmt.Errorf("rule %q: missing scope", r.Name)
+	}
+	for _, scope := range r.Scope {
+		if !slices.Contains(scopes, scope) {
+			return fmt.Errorf("rule %q: unknown scope %q: expected one of %s", r.Name, scope, strings.Join(scopes, ", "))
+		}
+	}
+	return nil
+}
+
+// Fields returns the names of the Jira custom fields read by the rule, to be
+// loaded in the field registry of the issues.
+func (r Rule) Fields() []string {
+	var names []string
+	r.cond.walk(func(field string) {
+		if custom := fieldsByName[field].custom; custom != "" && !slices.Contains(names, custom) {
+			names = append(names, custom)
+		}
+	})
+	return names
+}
+
+// JiraFields returns the IDs of the Jira fields read by the rule, system
+// fields and custom fields, to be requested when searching the issues. The
+// custom fields must be loaded in the registry.
+func (r Rule) JiraFields(registry *fields.Registry) []string {
+	var ids []string
+	r.cond.walk(func(name string) {
+		f := fieldsByName[name]
+		id := f.jira
+		if f.custom != "" {
+			id = registry.ID(f.custom)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	})
+	return ids
+}
+
+// Eval reports whether the issue satisfies the rule.
+func (r Rule) Eval(i issue.Issue) (bool, error) {
+	return r.cond.eval(i)
+}
+
+// Check evaluates the rule with the signature of the built-in triage checks.
+// The message of a failed rule names it. Warnings are logged and never fail.
+func (r Rule) Check(i issue.Issue) (bool, string, error) {
+	ok, err := r.Eval(i)
+	if err != nil {
+		return false, "", fmt.Errorf("rule %q: %w", r.Name, err)
+	}
+	if ok {
+		return true, "", nil
+	}
+	if r.Severity == SeverityWarning {
+		log.Printf("WARNING: issue %s: rule %q: %s", i.Key, r.Name, r.Message)
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%s (rule %q)", r.Message, r.Name), nil
+}
+
+// Rules is a set of rules.
+type Rules []Rule
+
+// Validate returns an error describing the first invalid rule.
+func (rs Rules) Validate() error {
+	seen := make(map[string]struct{}, len(rs))
+	for _, r := range rs {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if _, ok := seen[r.Name]; ok {
+			return fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		seen[r.Name] = struct{}{}
+	}
+	return nil
+}
+
+// For returns the rules run by the given command.
+func (rs Rules) For(scope string) Rules {
+	var scoped Rules
+	for _, r := range rs {
+		if slices.Contains(r.Scope, scope) {
+			scoped = append(scoped, r)
+		}
+	}
+	return scoped
+}
+
+// Fields returns the names of the Jira custom fields read by the rules.
+func (rs Rules) Fields() []string {
+	var names []string
+	for _, r := range rs {
+		for _, name := range r.Fields() {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// JiraFields returns the IDs of the Jira fields read by the rules.
+func (rs Rules) JiraFields(registry *fields.Registry) []string {
+	var ids []string
+	for _, r := range rs {
+		for _, id := range r.JiraFields(registry) {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// field is a field of the issues that rules can read.
+type field struct {
+	// jira is the ID of the Jira system field, if any.
+	jira string
+	// custom is the name of the Jira custom field, if any.
+	custom string
+	values func(issue.Issue) ([]string, error)
+}
+
+var fieldsByName = map[string]field{
+	"priority": {jira: query.FieldPriority, values: func(i issue.Issue) ([]string, error) {
+		if i.Fields.Priority == nil {
+			return nil, nil
+		}
+		return []string{i.Fields.Priority.Name}, nil
+	}},
+	"resolution": {jira: query.FieldResolution, values: func(i issue.Issue) ([]string, error) {
+		if i.Fields.Resolution == nil {
+			return nil, nil
+		}
+		return []string{i.Fields.Resolution.Name}, nil
+	}},
+	"status": {jira: query.FieldStatus, values: func(i issue.Issue) ([]string, error) {
+		if i.Fields.Status == nil {
+			return nil, nil
+		}
+		return []string{i.Fields.Status.Name}, nil
+	}},
+	"type": {jira: query.FieldIssueType, values: func(i issue.Issue) ([]string, error) {
+		return one(i.Fields.Type.Name, nil)
+	}},
+	"labels": {jira: query.FieldLabels, values: func(i issue.Issue) ([]string, error) {
+		return i.Fields.Labels, nil
+	}},
+	"components": {jira: query.FieldComponents, values: func(i issue.Issue) ([]string, error) {
+		names := make([]string, 0, len(i.Fields.Components))
+		for _, component := range i.Fields.Components {
+			names = append(names, component.Name)
+		}
+		return names, nil
+	}},
+	"assignee": {jira: query.FieldAssignee, values: func(i issue.Issue) ([]string, error) {
+		if i.Fields.Assignee == nil {
+			return nil, nil
+		}
+		return []string{i.Fields.Assignee.AccountID}, nil
+	}},
+	"release_note_type": {custom: fields.ReleaseNoteType, values: func(i issue.Issue) ([]string, error) {
+		return one(i.ReleaseNoteType())
+	}},
+	"release_note_text": {custom: fields.ReleaseNoteText, values: func(i issue.Issue) ([]string, error) {
+		return one(i.ReleaseNoteText())
+	}},
+	"release_blocker": {custom: fields.ReleaseBlocker, values: func(i issue.Issue) ([]string, error) {
+		rb, err := i.ReleaseBlocker()
+		return one(string(rb), err)
+	}},
+	"test_coverage": {custom: fields.TestCoverage, values: func(i issue.Issue) ([]string, error) {
+		tc, err := i.TestCoverage()
+		if err != nil || tc == issue.TestCoverageNone {
+			return nil, err
+		}
+		return []string{string(rune(tc))}, nil
+	}},
+	"cve": {custom: fields.CVE, values: func(i issue.Issue) ([]string, error) {
+		return one(i.CVEID())
+	}},
+	"need_info_from": {custom: fields.NeedInfoFrom, values: func(i issue.Issue) ([]string, error) {
+		return i.NeedInfoFrom()
+	}},
+	"target_version": {custom: fields.TargetVersion, values: func(i issue.Issue) ([]string, error) {
+		return i.TargetVersion()
+	}},
+	"severity": {custom: fields.Severity, values: func(i issue.Issue) ([]string, error) {
+		return one(i.Severity())
+	}},
+}
+
+// one returns the single value, or no value if it is empty.
+func one(value string, err error) ([]string, error) {
+	if err != nil || value == "" {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+// node is a node of the syntax tree of an expression.
+type node interface {
+	eval(issue.Issue) (bool, error)
+	walk(func(field string))
+}
+
+type unlessNode struct{ cond, except node }
+
+func (n unlessNode) eval(i issue.Issue) (bool, error) {
+	except, err := n.except.eval(i)
+	if err != nil || except {
+		return except, err
+	}
+	return n.cond.eval(i)
+}
+
+func (n unlessNode) walk(f func(string)) { n.cond.walk(f); n.except.walk(f) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(i issue.Issue) (bool, error) {
+	left, err := n.left.eval(i)
+	if err != nil || left {
+		return left, err
+	}
+	return n.right.eval(i)
+}
+
+func (n orNode) walk(f func(string)) { n.left.walk(f); n.right.walk(f) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(i issue.Issue) (bool, error) {
+	left, err := n.left.eval(i)
+	if err != nil || !left {
+		return false, err
+	}
+	return n.right.eval(i)
+}
+
+func (n andNode) walk(f func(string)) { n.left.walk(f); n.right.walk(f) }
+
+type notNode struct{ n node }
+
+func (n notNode) eval(i issue.Issue) (bool, error) {
+	v, err := n.n.eval(i)
+	return !v, err
+}
+
+func (n notNode) walk(f func(string)) { n.n.walk(f) }
+
+// inNode holds when any value of the field is in the list.
+type inNode struct {
+	field  string
+	values []string
+}
+
+func (n inNode) eval(i issue.Issue) (bool, error) {
+	values, err := fieldsByName[n.field].values(i)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range values {
+		for _, want := range n.values {
+			if strings.EqualFold(v, want) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (n inNode) walk(f func(string)) { f(n.field) }
+
+// emptyNode holds when the field has no value.
+type emptyNode struct{ field string }
+
+func (n emptyNode) eval(i issue.Issue) (bool, error) {
+	values, err := fieldsByName[n.field].values(i)
+	return len(values) == 0, err
+}
+
+func (n emptyNode) walk(f func(string)) { f(n.field) }
@@ -0,0 +1,172 @@
+package rules
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/fields"
+	"github.com/shiftstack/bugwatcher/pkg/issue"
+	"gopkg.in/yaml.v3"
+)
+
+var registry = fields.New(
+	map[string]string{
+		fields.ReleaseBlocker: "customfield_10847",
+		fields.TestCoverage:   "customfield_10638",
+	},
+)
+
+func parseIssue(t *testing.T, issueFields string) issue.Issue {
+	t.Helper()
+	var i jira.Issue
+	if err := json.Unmarshal([]byte(`{"key": "OCPBUGS-1", "fields": `+issueFields+`}`), &i); err != nil {
+		t.Fatal(err)
+	}
+	return issue.New(i, registry)
+}
+
+func TestEval(t *testing.T) {
+	undefined := parseIssue(t, `{"priority": {"name": "Undefined"}, "labels": ["Triaged", "UpgradeBlocker"]}`)
+	duplicate := parseIssue(t, `{"priority": {"name": "Undefined"}, "resolution": {"name": "Not a Bug"}}`)
+	proposed := parseIssue(t, `{
+		"priority": {"name": "Major"},
+		"customfield_10847": {"id": "16773", "value": "Proposed"},
+		"customfield_10638": [{"id": "15875", "value": "+"}]
+	}`)
+	empty := parseIssue(t, `{}`)
+
+	for _, tc := range [...]struct {
+		expr  string
+		issue issue.Issue
+		want  bool
+	}{
+		{`priority != Undefined unless resolution in (Duplicate, Not a Bug)`, undefined, false},
+		{`priority != Undefined unless resolution in (Duplicate, Not a Bug)`, duplicate, true},
+		{`priority != Undefined unless resolution in (Duplicate, Not a Bug)`, proposed, true},
+		{`priority is not empty`, empty, false},
+		{`priority is empty or priority = "major"`, proposed, true},
+		{`labels = UpgradeBlocker`, undefined, true},
+		{`labels not in (UpgradeBlocker, TechDebt)`, undefined, false},
+		{`labels is empty`, proposed, true},
+		{`release_blocker != Proposed`, proposed, false},
+		{`release_blocker != Proposed`, empty, true},
+		{`not (test_coverage = "+" and release_blocker = Proposed)`, proposed, false},
+		{`test_coverage in ("+", "-") or priority = Undefined and labels = Triaged`, undefined, true},
+		{`(test_coverage in ("+", "-") or priority = Undefined) and labels is empty`, undefined, false},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			n, err := parse(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := n.eval(tc.issue)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range [...]struct {
+		expr string
+		want string
+	}{
+		{``, "expected a field name"},
+		{`prio = Major`, `unknown field "prio"`},
+		{`priority Major`, "expected an operator"},
+		{`priority in (Major`, `expected "," or ")"`},
+		{`priority = `, "expected a value"},
+		{`priority = "Major`, "unterminated string"},
+		{`priority = Major)`, `unexpected ")"`},
+		{`priority ! Major`, `unexpected '!'`},
+		{`priority is not Major`, `expected "empty"`},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := parse(tc.expr)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+const rulesYAML = `
+- name: priority
+  expr: priority is not empty and priority != Undefined unless resolution in (Duplicate, Not a Bug)
+  message: the Priority assessment is missing
+  scope: [posttriage]
+- name: no-proposed-blocker
+  expr: release_blocker != Proposed
+  message: the issue is a proposed release blocker
+  severity: warning
+  scope: [posttriage, doctext]
+`
+
+func TestRules(t *testing.T) {
+	var rs Rules
+	if err := yaml.Unmarshal([]byte(rulesYAML), &rs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := rs.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := rs.For(ScopeDoctext); len(got) != 1 || got[0].Name != "no-proposed-blocker" {
+		t.Errorf("unexpected rules for doctext: %v", got)
+	}
+	if got := rs.Fields(); !slices.Equal(got, []string{fields.ReleaseBlocker}) {
+		t.Errorf("unexpected fields: %q", got)
+	}
+	if got, want := rs.JiraFields(registry), []string{"priority", "resolution", "customfield_10847"}; !slices.Equal(got, want) {
+		t.Errorf("expected the Jira fields %q, got %q", want, got)
+	}
+
+	undefined := parseIssue(t, `{"priority": {"name": "Undefined"}, "customfield_10847": {"id": "16773", "value": "Proposed"}}`)
+	ok, msg, err := rs[0].Check(undefined)
+	if err != nil || ok || msg != `the Priority assessment is missing (rule "priority")` {
+		t.Errorf("unexpected result: %t, %q, %v", ok, msg, err)
+	}
+	if ok, _, err := rs[1].Check(undefined); err != nil || !ok {
+		t.Errorf("expected warnings not to fail, got %t, %v", ok, err)
+	}
+}
+
+func TestRulesInvalid(t *testing.T) {
+	for _, tc := range [...]struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"bad expression", "- {name: a, expr: 'priority ==', message: m, scope: [posttriage]}", `rule "a"`},
+		{"bad name", "- {name: 'a b', expr: 'priority is empty', message: m, scope: [posttriage]}", "not a valid rule name"},
+		{"no message", "- {name: a, expr: 'priority is empty', scope: [posttriage]}", "missing message"},
+		{"bad severity", "- {name: a, expr: 'priority is empty', message: m, severity: fatal, scope: [posttriage]}", "unknown severity"},
+		{"misspelled key", "- {name: a, expr: 'priority is empty', message: m, severty: warning, scope: [posttriage]}", "field severty not found"},
+		{"bad scope", "- {name: a, expr: 'priority is empty', message: m, scope: [pretriage]}", "unknown scope"},
+		{"duplicate", "- {name: a, expr: 'priority is empty', message: m, scope: [doctext]}\n- {name: a, expr: 'priority is empty', message: m, scope: [doctext]}", "duplicate rule"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var rs Rules
+			err := yaml.Unmarshal([]byte(tc.yaml), &rs)
+			if err == nil {
+				err = rs.Validate()
+			}
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
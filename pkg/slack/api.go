@@ -0,0 +1,232 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+)
+
+// DefaultAPIURL is the base URL of the Slack Web API.
+const DefaultAPIURL = "https://slack.com/api/"
+
+// The Web API calls are attempted up to maxAttempts times while Slack
+// throttles them, waiting for the delay it requests up to maxRetryAfter.
+const (
+	maxAttempts   = 3
+	maxRetryAfter = time.Minute
+)
+
+// sleep is replaced in tests.
+var sleep = sleepContext
+
+// sleepContext waits for d, or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// API is a client of the Slack Web API, authenticated with a bot token. The
+// bot needs the chat:write scope to post, and im:write to send direct
+// messages.
+type API struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// NewAPI returns a client of the Web API at baseURL, e.g. DefaultAPIURL,
+// authenticated with the bot token.
+func NewAPI(baseURL, token string) *API {
+	return &API{
+		httpClient: &http.Client{Timeout: httpTimeout},
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/",
+		token:      token,
+	}
+}
+
+// Posted identifies a message posted with the Web API.
+type Posted struct {
+	// Channel is the ID of the channel holding the message.
+	Channel string
+	// TS is the timestamp of the message, which identifies it in its
+	// channel.
+	TS string
+}
+
+// Error is an error returned by the Web API, e.g. "channel_not_found".
+type Error struct {
+	Method string
+	Code   string
+}
+
+func (e *Error) Error() string {
+	return "slack " + e.Method + ": " + e.Code
+}
+
+// PostMessage posts the message to the channel, in the thread of the message
+// with timestamp threadTS if not empty. In dry-run mode, the message is
+// printed instead and the zero Posted is returned.
+func (a *API) PostMessage(ctx context.Context, channel, threadTS string, message Message) (Posted, error) {
+	payload := messagePayload(message)
+	payload["channel"] = channel
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+
+	var posted Posted
+	err := mutation.Apply(mutation.Record{
+		Action: "slack.post_message",
+		Target: channel,
+		Data:   payload,
+	}, func() error {
+		var res struct {
+			Channel string `json:"channel"`
+			TS      string `json:"ts"`
+		}
+		if err := a.call(ctx, "chat.postMessage", payload, &res); err != nil {
+			return err
+		}
+		posted = Posted{Channel: res.Channel, TS: res.TS}
+		return nil
+	})
+	return posted, err
+}
+
+// UpdateMessage replaces the content of a posted message. In dry-run mode,
+// the change is printed instead.
+func (a *API) UpdateMessage(ctx context.Context, posted Posted, message Message) error {
+	payload := messagePayload(message)
+	payload["channel"] = posted.Channel
+	payload["ts"] = posted.TS
+	// chat.update keeps the previous blocks unless blocks are given: an
+	// empty list removes them.
+	if len(message.Blocks) == 0 {
+		payload["blocks"] = []Block{}
+	}
+
+	return mutation.Apply(mutation.Record{
+		Action: "slack.update_message",
+		Target: posted.Channel,
+		Data:   payload,
+	}, func() error {
+		return a.call(ctx, "chat.update", payload, nil)
+	})
+}
+
+// messagePayload returns the fields of the Web API payloads describing the
+// message.
+func messagePayload(message Message) map[string]any {
+	payload := map[string]any{
+		"text":       message.Text,
+		"link_names": true,
+	}
+	if len(message.Blocks) > 0 {
+		payload["blocks"] = message.Blocks
+	}
+	return payload
+}
+
+// OpenConversation returns the ID of the direct message channel with the
+// user, e.g. "U012AB3CD". In dry-run mode, Slack is not called and the ID of
+// the user is returned instead, which chat.postMessage accepts as a channel.
+func (a *API) OpenConversation(ctx context.Context, user string) (string, error) {
+	payload := map[string]any{
+		"users": strings.TrimPrefix(user, "@"),
+	}
+
+	channel := strings.TrimPrefix(user, "@")
+	err := mutation.Apply(mutation.Record{
+		Action: "slack.open_conversation",
+		Target: channel,
+		Data:   payload,
+	}, func() error {
+		var res struct {
+			Channel struct {
+				ID string `json:"id"`
+			} `json:"channel"`
+		}
+		if err := a.call(ctx, "conversations.open", payload, &res); err != nil {
+			return err
+		}
+		channel = res.Channel.ID
+		return nil
+	})
+	return channel, err
+}
+
+// call sends the JSON payload to the Web API method, and decodes the response
+// into v if not nil. The throttled calls are retried after the delay requested
+// by Slack. See https://api.slack.com/apis/rate-limits
+func (a *API) call(ctx context.Context, method string, payload map[string]any, v any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling the %s payload: %w", method, err)
+	}
+
+	var res *http.Response
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+method, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("Authorization", "Bearer "+a.token)
+
+		res, err = a.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error calling slack %s: %w", method, err)
+		}
+		if res.StatusCode != http.StatusTooManyRequests || attempt == maxAttempts {
+			break
+		}
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		// Retry-After is in seconds
+		seconds, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		if err := sleep(ctx, min(max(time.Duration(seconds)*time.Second, time.Second), maxRetryAfter)); err != nil {
+			return fmt.Errorf("error calling slack %s: %w", method, err)
+		}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("unexpected status code %q calling slack %s", res.Status, method)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading the slack %s response: %w", method, err)
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(b, &status); err != nil {
+		return fmt.Errorf("error decoding the slack %s response: %w", method, err)
+	}
+	if !status.OK {
+		return &Error{Method: method, Code: status.Error}
+	}
+	if v != nil {
+		if err := json.Unmarshal(b, v); err != nil {
+			return fmt.Errorf("error decoding the slack %s response: %w", method, err)
+		}
+	}
+	return nil
+}
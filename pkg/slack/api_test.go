@@ -0,0 +1,105 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+)
+
+func TestAPI(t *testing.T) {
+	server := slacktest.NewServer(t)
+	api := NewAPI(server.APIURL(), slacktest.Token)
+	ctx := context.Background()
+
+	posted, err := api.PostMessage(ctx, "C012AB3CD", "", Message{Text: "<@U012AB3CD> please triage"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posted.Channel != "C012AB3CD" || posted.TS == "" {
+		t.Fatalf("unexpected message: %+v", posted)
+	}
+
+	reply, err := api.PostMessage(ctx, posted.Channel, posted.TS, Message{Text: "done"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := api.UpdateMessage(ctx, posted, Message{Text: "<@U012AB3CD> triaged"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := server.MessagesIn("C012AB3CD")
+	if len(messages) != 2 {
+		t.Fatalf("expected two messages, got %d", len(messages))
+	}
+	if m := messages[0]; m.Text != "<@U012AB3CD> triaged" || m.Updates != 1 || !m.HasMention("@U012AB3CD") {
+		t.Errorf("expected the message to be updated, got %+v", m)
+	}
+	if m := messages[1]; m.TS != reply.TS || m.ThreadTS != posted.TS {
+		t.Errorf("expected a reply in the thread %q, got %+v", posted.TS, m)
+	}
+
+	channel, err := api.OpenConversation(ctx, "@U012AB3CD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if channel != "DU012AB3CD" {
+		t.Errorf("expected the direct message channel DU012AB3CD, got %q", channel)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	server := slacktest.NewServer(t)
+	ctx := context.Background()
+
+	var apiErr *Error
+	_, err := NewAPI(server.APIURL(), "xoxb-wrong").PostMessage(ctx, "C012AB3CD", "", Message{Text: "hello"})
+	if !errors.As(err, &apiErr) || apiErr.Code != "invalid_auth" {
+		t.Errorf("expected invalid_auth, got %v", err)
+	}
+
+	api := NewAPI(server.APIURL(), slacktest.Token)
+	_, err = api.PostMessage(ctx, "C012AB3CD", "1.000000", Message{Text: "hello"})
+	if !errors.As(err, &apiErr) || apiErr.Code != "thread_not_found" {
+		t.Errorf("expected thread_not_found, got %v", err)
+	}
+	err = api.UpdateMessage(ctx, Posted{Channel: "C012AB3CD", TS: "1.000000"}, Message{Text: "hello"})
+	if !errors.As(err, &apiErr) || apiErr.Code != "message_not_found" {
+		t.Errorf("expected message_not_found, got %v", err)
+	}
+
+	sleep = func(context.Context, time.Duration) error { return nil }
+	t.Cleanup(func() { sleep = sleepContext })
+	server.FailNext(http.StatusTooManyRequests, maxAttempts)
+	if _, err := api.PostMessage(ctx, "C012AB3CD", "", Message{Text: "hello"}); err == nil || errors.As(err, &apiErr) {
+		t.Errorf("expected an HTTP error, got %v", err)
+	}
+	if got := len(server.Messages()); got != 0 {
+		t.Errorf("expected no message, got %d", got)
+	}
+}
+
+func TestAPIRetryAfter(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = sleepContext })
+
+	server := slacktest.NewServer(t)
+	server.FailNext(http.StatusTooManyRequests, maxAttempts-1)
+	if _, err := NewAPI(server.APIURL(), slacktest.Token).PostMessage(context.Background(), "C012AB3CD", "", Message{Text: "hello"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(server.Messages()); got != 1 {
+		t.Errorf("expected the message to be posted once, got %d", got)
+	}
+	if want := []time.Duration{time.Second, time.Second}; !slices.Equal(waits, want) {
+		t.Errorf("expected to wait for the Retry-After delays %v, got %v", want, waits)
+	}
+}
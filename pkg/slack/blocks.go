@@ -0,0 +1,151 @@
+package slack
+
+import (
+	"strings"
+)
+
+// Message is a Slack message: Block Kit blocks, and their plain-text
+// fallback. The fallback is shown in the notifications, and by the clients
+// and webhooks that cannot render the blocks.
+type Message struct {
+	// Text is the fallback, in Slack mrkdwn.
+	Text string
+	// Blocks are the Block Kit layout of the message, if any.
+	Blocks []Block
+}
+
+// Block is a Block Kit layout block. Only the fields of its type are set.
+// See https://api.slack.com/reference/block-kit/blocks
+type Block struct {
+	Type    string `json:"type"`
+	BlockID string `json:"block_id,omitempty"`
+	// Text is the text of a section.
+	Text *Text `json:"text,omitempty"`
+	// Fields are the columns of a section.
+	Fields []Text `json:"fields,omitempty"`
+	// Expand shows the whole text of a section. Otherwise, Slack folds a
+	// long text behind a "See more" link.
+	Expand bool `json:"expand,omitempty"`
+	// Elements are the texts of a context block, or the Element values of
+	// an actions block.
+	Elements []any `json:"elements,omitempty"`
+}
+
+// Text is a Block Kit text object.
+type Text struct {
+	// Type is "mrkdwn" or "plain_text".
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Markdown returns a mrkdwn text object.
+func Markdown(text string) Text {
+	return Text{Type: "mrkdwn", Text: text}
+}
+
+// PlainText returns a plain text object.
+func PlainText(text string) Text {
+	return Text{Type: "plain_text", Text: text}
+}
+
+// Section returns a section block with the mrkdwn text, shown in full, and
+// the given columns.
+func Section(text string, fields ...Text) Block {
+	t := Markdown(text)
+	return Block{Type: "section", Text: &t, Fields: fields, Expand: true}
+}
+
+// CollapsibleSection returns a section block with the mrkdwn text, which
+// Slack folds behind a "See more" link when it is long.
+func CollapsibleSection(text string) Block {
+	t := Markdown(text)
+	return Block{Type: "section", Text: &t}
+}
+
+// Context returns a context block, displaying the mrkdwn texts in small
+// print.
+func Context(texts ...string) Block {
+	elements := make([]any, 0, len(texts))
+	for _, text := range texts {
+		elements = append(elements, Markdown(text))
+	}
+	return Block{Type: "context", Elements: elements}
+}
+
+// Divider returns a divider block.
+func Divider() Block {
+	return Block{Type: "divider"}
+}
+
+// Actions returns an actions block holding the interactive elements. The
+// block ID tells which block an interaction comes from.
+func Actions(blockID string, elements ...Element) Block {
+	list := make([]any, 0, len(elements))
+	for _, e := range elements {
+		list = append(list, e)
+	}
+	return Block{Type: "actions", BlockID: blockID, Elements: list}
+}
+
+// Element is an interactive Block Kit element. Only the fields of its type
+// are set. See https://api.slack.com/reference/block-kit/block-elements
+type Element struct {
+	Type string `json:"type"`
+	// ActionID identifies the action in the interaction payloads.
+	ActionID string `json:"action_id"`
+	// Text is the label of a button.
+	Text *Text `json:"text,omitempty"`
+	// Placeholder is the label of a select menu.
+	Placeholder *Text `json:"placeholder,omitempty"`
+	// Value is sent with the interaction payload of a button.
+	Value string `json:"value,omitempty"`
+	// Style is "primary" or "danger" for a colored button.
+	Style string `json:"style,omitempty"`
+	// Options are the choices of a static select menu.
+	Options []Option `json:"options,omitempty"`
+}
+
+// Option is a choice of a select menu.
+type Option struct {
+	Text  Text   `json:"text"`
+	Value string `json:"value"`
+}
+
+// Button returns a button labeled text, sending value when clicked.
+func Button(actionID, text, value string) Element {
+	t := PlainText(text)
+	return Element{Type: "button", ActionID: actionID, Text: &t, Value: value}
+}
+
+// UsersSelect returns a menu selecting a user of the workspace.
+func UsersSelect(actionID, placeholder string) Element {
+	t := PlainText(placeholder)
+	return Element{Type: "users_select", ActionID: actionID, Placeholder: &t}
+}
+
+// StaticSelect returns a menu selecting one of the values, each labeled with
+// itself.
+func StaticSelect(actionID, placeholder string, values ...string) Element {
+	t := PlainText(placeholder)
+	options := make([]Option, 0, len(values))
+	for _, value := range values {
+		options = append(options, Option{Text: PlainText(value), Value: value})
+	}
+	return Element{Type: "static_select", ActionID: actionID, Placeholder: &t, Options: options}
+}
+
+// mrkdwnEscaper escapes the control characters of mrkdwn. Slack asks not to
+// escape anything else.
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape escapes the text so that it is displayed as is in mrkdwn, and cannot
+// inject mentions or links.
+func Escape(text string) string {
+	return mrkdwnEscaper.Replace(text)
+}
+
+// Link returns a link to url, labeled text, in Slack mrkdwn. The text is
+// displayed as is.
+func Link(url, text string) string {
+	return "<" + url + "|" + Escape(text) + ">"
+}
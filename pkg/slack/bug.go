@@ -0,0 +1,201 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/shiftstack/bugwatcher/pkg/query"
+)
+
+// BugFields are the Jira fields read by NewBug, to request when searching
+// the bugs to notify about.
+var BugFields = []string{
+	query.FieldSummary,
+	query.FieldPriority,
+	query.FieldComponents,
+	query.FieldCreated,
+	query.FieldReporter,
+	query.FieldStatus,
+}
+
+// maxBugSections is the number of bugs detailed in a message. Slack accepts
+// up to 50 blocks per message.
+const maxBugSections = 20
+
+// maxInteractiveBugSections is the number of bugs detailed in a message with
+// their actions, each taking a block more.
+const maxInteractiveBugSections = 15
+
+// maxSectionText is the maximum length of the text of a section.
+const maxSectionText = 3000
+
+// Bug holds the details of a bug shown in the notifications.
+type Bug struct {
+	Key string
+	// URL is the address of the web page of the bug.
+	URL       string
+	Summary   string
+	Priority  string
+	Component string
+	Reporter  string
+	Status    string
+	Created   time.Time
+}
+
+// NewBug returns the details of the issue, whose web page is at url. The
+// fields that were not returned by Jira are left empty.
+func NewBug(issue jira.Issue, url string) Bug {
+	b := Bug{Key: issue.Key, URL: url}
+	if f := issue.Fields; f != nil {
+		b.Summary = f.Summary
+		if f.Priority != nil {
+			b.Priority = f.Priority.Name
+		}
+		if len(f.Components) > 0 {
+			b.Component = f.Components[0].Name
+		}
+		if f.Reporter != nil {
+			b.Reporter = f.Reporter.DisplayName
+		}
+		if f.Status != nil {
+			b.Status = f.Status.Name
+		}
+		b.Created = time.Time(f.Created)
+	}
+	return b
+}
+
+// Section returns a section block detailing the bug: its key and summary,
+// then its priority, component, status, reporter and age.
+func (b Bug) Section(now time.Time) Block {
+	return Section(b.title(),
+		Markdown("*Priority*\n"+orNone(b.Priority)),
+		Markdown("*Component*\n"+orNone(b.Component)),
+		Markdown("*Status*\n"+orNone(b.Status)),
+		Markdown("*Reporter*\n"+orNone(b.Reporter)),
+		Markdown("*Age*\n"+Age(b.Created, now)),
+	)
+}
+
+// Line returns a one-line description of the bug, in mrkdwn.
+func (b Bug) Line(now time.Time) string {
+	return "• " + b.title() + " (" + strings.Join([]string{
+		orNone(b.Status),
+		orNone(b.Priority),
+		Age(b.Created, now),
+	}, ", ") + ")"
+}
+
+// title returns the link to the bug followed by its summary, in mrkdwn.
+func (b Bug) title() string {
+	title := "*" + Link(b.URL, b.Key) + "*"
+	if b.Summary != "" {
+		title += " " + Escape(b.Summary)
+	}
+	return title
+}
+
+// orNone returns the escaped value, or a placeholder if it is empty.
+func orNone(value string) string {
+	if value == "" {
+		return "_none_"
+	}
+	return Escape(value)
+}
+
+// Age returns the time elapsed since created, rounded down to the largest
+// unit, e.g. "3 days".
+func Age(created, now time.Time) string {
+	if created.IsZero() {
+		return "_unknown_"
+	}
+	d := now.Sub(created)
+	switch {
+	case d < time.Hour:
+		return "less than an hour"
+	case d < 24*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	case d < 60*24*time.Hour:
+		return plural(int(d/(24*time.Hour)), "day")
+	default:
+		return plural(int(d/(30*24*time.Hour)), "month")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// BugsMessage returns a message introducing the bugs with the mrkdwn intro,
+// then detailing each of them. Beyond maxBugSections bugs, the remaining ones
+// are only linked. The fallback is the plain-text version of the message.
+func BugsMessage(fallback, intro string, bugs []Bug, now time.Time) Message {
+	return bugsMessage(fallback, intro, bugs, now, maxBugSections, nil)
+}
+
+// InteractiveBugsMessage is BugsMessage with the actions returned by
+// BugActions under each detailed bug. Fewer bugs are detailed, so that their
+// blocks fit in the message.
+func InteractiveBugsMessage(fallback, intro string, bugs []Bug, now time.Time, handoff []string) Message {
+	return bugsMessage(fallback, intro, bugs, now, maxInteractiveBugSections, func(b Bug) Block {
+		return BugActions(b.Key, handoff)
+	})
+}
+
+// bugsMessage details up to sections bugs, followed by their actions block if
+// actions is not nil.
+func bugsMessage(fallback, intro string, bugs []Bug, now time.Time, sections int, actions func(Bug) Block) Message {
+	blocks := []Block{Section(intro)}
+	for i, b := range bugs {
+		if i == sections {
+			blocks = append(blocks, Context(moreBugs(bugs[i:])))
+			break
+		}
+		blocks = append(blocks, Divider(), b.Section(now))
+		if actions != nil {
+			blocks = append(blocks, actions(b))
+		}
+	}
+	return Message{Text: fallback, Blocks: blocks}
+}
+
+// moreBugs returns the text linking the bugs that are not detailed. The bugs
+// whose links do not fit in maxSectionText characters are counted at the end.
+func moreBugs(bugs []Bug) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "And %d more:", len(bugs))
+	for i, b := range bugs {
+		link := " " + Link(b.URL, b.Key)
+		more := fmt.Sprintf(" …and %d more", len(bugs)-i)
+		if text.Len()+len(link)+len(more) > maxSectionText {
+			text.WriteString(more)
+			break
+		}
+		text.WriteString(link)
+	}
+	return text.String()
+}
+
+// BugList returns a collapsible section listing the bugs, one per line.
+// The bugs that do not fit in a section are counted on the last line.
+func BugList(bugs []Bug, now time.Time) Block {
+	var list strings.Builder
+	for i, b := range bugs {
+		line := b.Line(now)
+		more := fmt.Sprintf("…and %d more", len(bugs)-i)
+		if i > 0 {
+			line, more = "\n"+line, "\n"+more
+		}
+		if list.Len()+len(line)+len(more) > maxSectionText {
+			list.WriteString(more)
+			break
+		}
+		list.WriteString(line)
+	}
+	return CollapsibleSection(list.String())
+}
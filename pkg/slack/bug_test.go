@@ -0,0 +1,149 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+var now = time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC)
+
+func TestEscape(t *testing.T) {
+	for _, tc := range [...]struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"<!channel> & <@U012AB3CD>", "&lt;!channel&gt; &amp; &lt;@U012AB3CD&gt;"},
+		{"*bold* _italic_ `code`", "*bold* _italic_ `code`"},
+		{"&amp;", "&amp;amp;"},
+	} {
+		if got := Escape(tc.text); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.text, tc.want, got)
+		}
+	}
+
+	if got, want := Link("https://example.com", "a <b> | c"), "<https://example.com|a &lt;b&gt; | c>"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAge(t *testing.T) {
+	for _, tc := range [...]struct {
+		created time.Time
+		want    string
+	}{
+		{time.Time{}, "_unknown_"},
+		{now.Add(-time.Minute), "less than an hour"},
+		{now.Add(-time.Hour), "1 hour"},
+		{now.Add(-23 * time.Hour), "23 hours"},
+		{now.Add(-50 * time.Hour), "2 days"},
+		{now.Add(-59 * 24 * time.Hour), "59 days"},
+		{now.Add(-100 * 24 * time.Hour), "3 months"},
+	} {
+		if got := Age(tc.created, now); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", now.Sub(tc.created), tc.want, got)
+		}
+	}
+}
+
+func TestNewBug(t *testing.T) {
+	var issue jira.Issue
+	if err := json.Unmarshal([]byte(`{
+		"key": "OCPBUGS-1",
+		"fields": {
+			"summary": "Ports <leak> & stay",
+			"priority": {"name": "Major"},
+			"components": [{"name": "Networking / kuryr"}],
+			"reporter": {"displayName": "Alice"},
+			"status": {"name": "New"},
+			"created": "2026-10-18T12:00:00.000+0000"
+		}
+	}`), &issue); err != nil {
+		t.Fatal(err)
+	}
+
+	bug := NewBug(issue, "https://jira.example.com/browse/OCPBUGS-1")
+	section := bug.Section(now)
+	if want := "*<https://jira.example.com/browse/OCPBUGS-1|OCPBUGS-1>* Ports &lt;leak&gt; &amp; stay"; section.Text.Text != want {
+		t.Errorf("expected the title %q, got %q", want, section.Text.Text)
+	}
+	var fields []string
+	for _, f := range section.Fields {
+		fields = append(fields, f.Text)
+	}
+	if got, want := strings.Join(fields, "|"), "*Priority*\nMajor|*Component*\nNetworking / kuryr|*Status*\nNew|*Reporter*\nAlice|*Age*\n3 days"; got != want {
+		t.Errorf("expected the fields %q, got %q", want, got)
+	}
+
+	if got, want := bug.Line(now), "• *<https://jira.example.com/browse/OCPBUGS-1|OCPBUGS-1>* Ports &lt;leak&gt; &amp; stay (New, Major, 3 days)"; got != want {
+		t.Errorf("expected the line %q, got %q", want, got)
+	}
+
+	if got, want := NewBug(jira.Issue{Key: "OCPBUGS-2"}, "u").Line(now), "• *<u|OCPBUGS-2>* (_none_, _none_, _unknown_)"; got != want {
+		t.Errorf("expected the line %q, got %q", want, got)
+	}
+}
+
+func TestBugsMessage(t *testing.T) {
+	bugs := make([]Bug, 25)
+	for i := range bugs {
+		bugs[i] = Bug{Key: "OCPBUGS-" + strconv.Itoa(i), URL: "u"}
+	}
+
+	message := BugsMessage("fallback", "<@U012AB3CD> please triage these bugs:", bugs, now)
+	if message.Text != "fallback" {
+		t.Errorf("expected the fallback text, got %q", message.Text)
+	}
+	// The intro, a divider and a section per detailed bug, and the others.
+	if got, want := len(message.Blocks), 1+2*maxBugSections+1; got != want {
+		t.Fatalf("expected %d blocks, got %d", want, got)
+	}
+	last := message.Blocks[len(message.Blocks)-1]
+	if last.Type != "context" || !strings.HasPrefix(last.Elements[0].(Text).Text, "And 5 more: <u|OCPBUGS-20>") {
+		t.Errorf("unexpected last block: %+v", last)
+	}
+
+	bugs = make([]Bug, 100)
+	for i := range bugs {
+		bugs[i] = Bug{Key: "OCPBUGS-" + strconv.Itoa(10000+i), URL: "https://jira.example.com/browse/OCPBUGS-" + strconv.Itoa(10000+i)}
+	}
+	message = BugsMessage("fallback", "intro", bugs, now)
+	text := message.Blocks[len(message.Blocks)-1].Elements[0].(Text).Text
+	if len(text) > maxSectionText {
+		t.Errorf("expected at most %d characters, got %d", maxSectionText, len(text))
+	}
+	links := strings.Count(text, "<https://")
+	if want := fmt.Sprintf(" …and %d more", 80-links); !strings.HasPrefix(text, "And 80 more: ") || !strings.HasSuffix(text, want) {
+		t.Errorf("expected the links to end with %q, got %q", want, text)
+	}
+}
+
+func TestBugList(t *testing.T) {
+	bugs := make([]Bug, 100)
+	for i := range bugs {
+		bugs[i] = Bug{Key: "OCPBUGS-1", URL: "https://jira.example.com/browse/OCPBUGS-1", Summary: strings.Repeat("x", 60)}
+	}
+
+	block := BugList(bugs, now)
+	if block.Type != "section" || block.Expand {
+		t.Errorf("expected a collapsible section, got %+v", block)
+	}
+	text := block.Text.Text
+	if len(text) > maxSectionText {
+		t.Errorf("expected at most %d characters, got %d", maxSectionText, len(text))
+	}
+	lines := strings.Split(text, "\n")
+	if last := lines[len(lines)-1]; last != "…and "+strconv.Itoa(100-len(lines)+1)+" more" {
+		t.Errorf("unexpected last line %q", last)
+	}
+
+	if got := BugList(bugs[:2], now).Text.Text; strings.Count(got, "\n") != 1 || strings.Contains(got, "more") {
+		t.Errorf("expected two lines, got %q", got)
+	}
+}
@@ -0,0 +1,177 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+)
+
+// The action IDs of the interactive elements attached to the bugs.
+const (
+	ActionTriage        = "bugwatcher_triage"
+	ActionReassign      = "bugwatcher_reassign"
+	ActionSnooze        = "bugwatcher_snooze"
+	ActionMoveComponent = "bugwatcher_move_component"
+)
+
+// bugBlockPrefix starts the ID of the actions block of a bug, followed by the
+// issue key.
+const bugBlockPrefix = "bugwatcher_bug:"
+
+// BugActions returns the actions block of the bug: marking it as triaged,
+// reassigning it, snoozing its reminders, and moving it to one of the handoff
+// components if any.
+func BugActions(key string, handoff []string) Block {
+	triage := Button(ActionTriage, "Mark Triaged", key)
+	triage.Style = "primary"
+	elements := []Element{
+		triage,
+		UsersSelect(ActionReassign, "Reassign to…"),
+		Button(ActionSnooze, "Snooze 2 days", key),
+	}
+	if len(handoff) > 0 {
+		elements = append(elements, StaticSelect(ActionMoveComponent, "Not ours (move component)", handoff...))
+	}
+	return Actions(bugBlockPrefix+key, elements...)
+}
+
+// BugKey returns the issue key of the actions block returned by BugActions,
+// from its block ID. The returned boolean is false if the block is not the
+// actions block of a bug.
+func BugKey(blockID string) (string, bool) {
+	key, ok := strings.CutPrefix(blockID, bugBlockPrefix)
+	return key, ok && key != ""
+}
+
+// Interaction is the payload sent by Slack when a user clicks a button or
+// picks a value in a menu of a message. See
+// https://api.slack.com/reference/interaction-payloads/block-actions
+type Interaction struct {
+	// Type is "block_actions" for the interactions with a message.
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	// ResponseURL is where to send the responses to the interaction, for
+	// 30 minutes.
+	ResponseURL string `json:"response_url"`
+	// Message is the message the interaction comes from. Its blocks are
+	// those sent by bugwatcher.
+	Message Message  `json:"message"`
+	Actions []Action `json:"actions"`
+}
+
+// Action is an interaction with an element.
+type Action struct {
+	ActionID string `json:"action_id"`
+	BlockID  string `json:"block_id"`
+	// Value is the value of a button.
+	Value string `json:"value"`
+	// SelectedUser is the ID of the user picked in a users select menu.
+	SelectedUser string `json:"selected_user"`
+	// SelectedOption is the option picked in a static select menu.
+	SelectedOption struct {
+		Value string `json:"value"`
+	} `json:"selected_option"`
+}
+
+// ParseInteraction decodes the body of an interaction request, a form holding
+// the JSON payload.
+func ParseInteraction(body []byte) (Interaction, error) {
+	var interaction Interaction
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return interaction, fmt.Errorf("error decoding the interaction form: %w", err)
+	}
+	if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil {
+		return interaction, fmt.Errorf("error decoding the interaction payload: %w", err)
+	}
+	return interaction, nil
+}
+
+// maxRequestAge is the maximum age of a signed request, to prevent replays.
+const maxRequestAge = 5 * time.Minute
+
+// ErrInvalidSignature is returned by VerifyRequest for the requests that were
+// not signed by Slack.
+var ErrInvalidSignature = errors.New("invalid Slack request signature")
+
+// Signature returns the signature of a request, as sent by Slack in the
+// X-Slack-Signature header. See
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func Signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequest checks that the request with the given headers and body was
+// signed by Slack with the signing secret of the app, less than five minutes
+// before now.
+func VerifyRequest(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("%w: the request is %s old", ErrInvalidSignature, age.Round(time.Second))
+	}
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(Signature(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Respond sends the message to the response URL of an interaction. With
+// replaceOriginal, it replaces the message the interaction comes from;
+// otherwise it is only shown to the user who interacted. In dry-run mode, the
+// response is printed instead.
+func (c Client) Respond(ctx context.Context, responseURL string, message Message, replaceOriginal bool) error {
+	payload := map[string]any{
+		"text":             message.Text,
+		"replace_original": replaceOriginal,
+	}
+	if len(message.Blocks) > 0 {
+		payload["blocks"] = message.Blocks
+	}
+
+	return mutation.Apply(mutation.Record{
+		Action: "slack.respond",
+		Target: "response_url",
+		Data:   payload,
+	}, func() error {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error marshalling the response payload: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error sending the response: %w", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code %q sending the response", res.Status)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,138 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+)
+
+func TestVerifyRequest(t *testing.T) {
+	const secret = "8f742231b10e8888abcd99yyyzzz85a5"
+	body := []byte("payload=%7B%7D")
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	for _, tc := range [...]struct {
+		name      string
+		timestamp string
+		signature string
+		now       time.Time
+		valid     bool
+	}{
+		{"valid", timestamp, Signature(secret, timestamp, body), now, true},
+		{"late but valid", timestamp, Signature(secret, timestamp, body), now.Add(4 * time.Minute), true},
+		{"wrong secret", timestamp, Signature("other", timestamp, body), now, false},
+		{"missing signature", timestamp, "", now, false},
+		{"replayed", timestamp, Signature(secret, timestamp, body), now.Add(6 * time.Minute), false},
+		{"from the future", timestamp, Signature(secret, timestamp, body), now.Add(-6 * time.Minute), false},
+		{"missing timestamp", "", Signature(secret, "", body), now, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			header := make(http.Header)
+			header.Set("X-Slack-Request-Timestamp", tc.timestamp)
+			header.Set("X-Slack-Signature", tc.signature)
+			err := VerifyRequest(secret, header, body, tc.now)
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("expected %v, got %v", ErrInvalidSignature, err)
+			}
+		})
+	}
+}
+
+func TestParseInteraction(t *testing.T) {
+	message := InteractiveBugsMessage("fallback", "intro", []Bug{{Key: "OCPBUGS-1", URL: "u"}}, now, []string{"Networking / ovn-kubernetes"})
+	payload, err := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]any{"id": "U012AB3CD"},
+		"response_url": "https://hooks.slack.com/actions/T012AB3CD/1/abc",
+		"message":      map[string]any{"text": message.Text, "blocks": message.Blocks},
+		"actions": []map[string]any{{
+			"action_id":       ActionMoveComponent,
+			"block_id":        "bugwatcher_bug:OCPBUGS-1",
+			"selected_option": map[string]any{"value": "Networking / ovn-kubernetes"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interaction, err := ParseInteraction([]byte(url.Values{"payload": {string(payload)}}.Encode()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interaction.User.ID != "U012AB3CD" || len(interaction.Actions) != 1 {
+		t.Fatalf("unexpected interaction: %+v", interaction)
+	}
+	action := interaction.Actions[0]
+	if key, ok := BugKey(action.BlockID); !ok || key != "OCPBUGS-1" {
+		t.Errorf("expected the key OCPBUGS-1, got %q", key)
+	}
+	if action.SelectedOption.Value != "Networking / ovn-kubernetes" {
+		t.Errorf("unexpected selected option %q", action.SelectedOption.Value)
+	}
+	if got := interaction.Message.Blocks; len(got) != 4 || got[3].Type != "actions" || got[3].BlockID != "bugwatcher_bug:OCPBUGS-1" {
+		t.Errorf("expected the blocks of the message, got %+v", got)
+	}
+
+	if _, ok := BugKey("bugwatcher_bug:"); ok {
+		t.Errorf("expected no key in an empty block ID")
+	}
+	if _, err := ParseInteraction([]byte("payload=%7B")); err == nil {
+		t.Errorf("expected an error for an invalid payload")
+	}
+}
+
+func TestInteractiveBugsMessage(t *testing.T) {
+	bugs := make([]Bug, 25)
+	for i := range bugs {
+		bugs[i] = Bug{Key: "OCPBUGS-" + strconv.Itoa(i), URL: "u"}
+	}
+
+	message := InteractiveBugsMessage("fallback", "intro", bugs, now, nil)
+	// The intro, a divider, a section and actions per detailed bug, and the
+	// others.
+	if got, want := len(message.Blocks), 1+3*maxInteractiveBugSections+1; got != want || got > 50 {
+		t.Fatalf("expected %d blocks, got %d", want, got)
+	}
+	actions := message.Blocks[3]
+	if actions.BlockID != "bugwatcher_bug:OCPBUGS-0" || len(actions.Elements) != 3 {
+		t.Errorf("expected the actions of OCPBUGS-0 without the handoff menu, got %+v", actions)
+	}
+
+	if got := len(BugActions("OCPBUGS-1", []string{"Networking / ovn-kubernetes"}).Elements); got != 4 {
+		t.Errorf("expected the handoff menu, got %d elements", got)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	server := slacktest.NewServer(t)
+	ctx := context.Background()
+
+	if err := New().Respond(ctx, server.ResponseURL(), Message{Text: "updated", Blocks: []Block{Context("done")}}, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := New().Respond(ctx, server.ResponseURL(), Message{Text: "failed"}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	responses := server.Responses()
+	if len(responses) != 2 || !responses[0].ReplaceOriginal || responses[1].ReplaceOriginal {
+		t.Fatalf("unexpected responses: %+v", responses)
+	}
+	if got := responses[0].BlockTexts(); len(got) != 1 || got[0] != "done" {
+		t.Errorf("expected the blocks to be sent, got %q", got)
+	}
+
+	server.FailNext(http.StatusNotFound, 1)
+	if err := New().Respond(ctx, server.ResponseURL(), Message{Text: "expired"}, true); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,151 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Notification is a message of a subcommand, with what is needed to route it.
+type Notification struct {
+	Message
+	// User is the Slack ID of the person the notification is for, e.g.
+	// "@U012AB3CD", if any.
+	User string
+	// Component is the Jira component of the bugs of the notification, if
+	// any.
+	Component string
+	// Thread is the message to reply to, if any.
+	Thread Posted
+}
+
+// Notifier sends the notifications of the subcommands.
+//
+// With the Web API, a notification goes, in order of preference: to the
+// thread it replies to; to the user in a direct message, if enabled; to the
+// channel of its component; to the default channel. Without the Web API, or
+// when none of these apply, it is posted to the webhook.
+type Notifier struct {
+	webhook Client
+	hook    string
+
+	api               *API
+	channel           string
+	componentChannels map[string]string
+	directMessages    bool
+
+	mu sync.Mutex
+	// conversations are the direct message channels, by user.
+	conversations map[string]string
+}
+
+// NotifierOption configures a Notifier.
+type NotifierOption func(*Notifier)
+
+// WithWebhook posts the notifications to the incoming webhook, unless they
+// can be sent with the Web API.
+func WithWebhook(hook string) NotifierOption {
+	return func(n *Notifier) { n.hook = hook }
+}
+
+// WithAPI sends the notifications with the Web API, to the default channel
+// with the given ID unless a more specific destination applies. The channel
+// can be empty, to post to the webhook instead.
+func WithAPI(api *API, channel string) NotifierOption {
+	return func(n *Notifier) { n.api, n.channel = api, channel }
+}
+
+// WithComponentChannels posts the notifications about the bugs of a
+// component to its channel, by component name. It requires WithAPI.
+func WithComponentChannels(channels map[string]string) NotifierOption {
+	return func(n *Notifier) { n.componentChannels = channels }
+}
+
+// WithDirectMessages sends the notifications for a user in a direct message.
+// It requires WithAPI.
+func WithDirectMessages(enabled bool) NotifierOption {
+	return func(n *Notifier) { n.directMessages = enabled }
+}
+
+// NewNotifier returns a notifier configured with the given options.
+func NewNotifier(opts ...NotifierOption) *Notifier {
+	n := &Notifier{
+		webhook:       New(),
+		conversations: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// ErrNoDestination is returned when a notification cannot be routed
+// anywhere.
+var ErrNoDestination = errors.New("no Slack channel nor webhook to post the notification to")
+
+// Notify sends the notification. The message is returned if it was posted
+// with the Web API, so that it can be replied to or updated; otherwise the
+// zero Posted is returned.
+func (n *Notifier) Notify(ctx context.Context, notification Notification) (Posted, error) {
+	if n.api != nil {
+		channel, threadTS, err := n.route(ctx, notification)
+		if err != nil {
+			return Posted{}, err
+		}
+		if channel != "" {
+			return n.api.PostMessage(ctx, channel, threadTS, notification.Message)
+		}
+	}
+
+	if n.hook == "" {
+		return Posted{}, ErrNoDestination
+	}
+	return Posted{}, n.webhook.SendMessage(n.hook, notification.Message)
+}
+
+// Update replaces the content of a message returned by Notify. Messages
+// posted to the webhook cannot be updated: they are left unchanged.
+func (n *Notifier) Update(ctx context.Context, posted Posted, message Message) error {
+	if n.api == nil || posted.TS == "" {
+		return nil
+	}
+	return n.api.UpdateMessage(ctx, posted, message)
+}
+
+// route returns the channel of the notification with the Web API, and the
+// thread to post to if any. The channel is empty if the notification is for
+// the webhook.
+func (n *Notifier) route(ctx context.Context, notification Notification) (string, string, error) {
+	switch {
+	case notification.Thread.TS != "":
+		return notification.Thread.Channel, notification.Thread.TS, nil
+	case notification.User != "" && n.directMessages:
+		channel, err := n.conversation(ctx, notification.User)
+		return channel, "", err
+	case n.componentChannels[notification.Component] != "":
+		return n.componentChannels[notification.Component], "", nil
+	default:
+		return n.channel, "", nil
+	}
+}
+
+// conversation returns the direct message channel with the user, opening it
+// on first use.
+func (n *Notifier) conversation(ctx context.Context, user string) (string, error) {
+	n.mu.Lock()
+	channel, ok := n.conversations[user]
+	n.mu.Unlock()
+	if ok {
+		return channel, nil
+	}
+
+	channel, err := n.api.OpenConversation(ctx, user)
+	if err != nil {
+		return "", err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.conversations[user] = channel
+	return channel, nil
+}
@@ -0,0 +1,138 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+)
+
+func TestNotifier(t *testing.T) {
+	server := slacktest.NewServer(t)
+	ctx := context.Background()
+
+	notifier := NewNotifier(
+		WithWebhook(server.URL),
+		WithAPI(NewAPI(server.APIURL(), slacktest.Token), "C0TEAM0001"),
+		WithComponentChannels(map[string]string{"Networking / kuryr": "C0KURYR001"}),
+		WithDirectMessages(true),
+	)
+
+	for _, tc := range [...]struct {
+		name         string
+		notification Notification
+		channel      string
+	}{
+		{"user", Notification{Message: Message{Text: "hello"}, User: "@U012AB3CD", Component: "Networking / kuryr"}, "DU012AB3CD"},
+		{"component", Notification{Message: Message{Text: "hello"}, Component: "Networking / kuryr"}, "C0KURYR001"},
+		{"other component", Notification{Message: Message{Text: "hello"}, Component: "HyperShift / OpenStack"}, "C0TEAM0001"},
+		{"no component", Notification{Message: Message{Text: "hello"}}, "C0TEAM0001"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			posted, err := notifier.Notify(ctx, tc.notification)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if posted.Channel != tc.channel {
+				t.Errorf("expected the channel %q, got %q", tc.channel, posted.Channel)
+			}
+		})
+	}
+
+	parent, err := notifier.Notify(ctx, Notification{Message: Message{Text: "parent"}, Component: "Networking / kuryr"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reply, err := notifier.Notify(ctx, Notification{Message: Message{Text: "reply"}, User: "@U012AB3CD", Thread: parent})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply.Channel != parent.Channel {
+		t.Errorf("expected the reply in %q, got %q", parent.Channel, reply.Channel)
+	}
+	if err := notifier.Update(ctx, parent, Message{Text: "parent, updated"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := server.MessagesIn(parent.Channel); len(got) != 3 || got[1].Text != "parent, updated" || got[2].ThreadTS != parent.TS {
+		t.Errorf("unexpected messages in %s: %+v", parent.Channel, got)
+	}
+
+	if got := len(server.MessagesIn("")); got != 0 {
+		t.Errorf("expected no message on the webhook, got %d", got)
+	}
+}
+
+func TestNotifierWebhook(t *testing.T) {
+	server := slacktest.NewServer(t)
+	ctx := context.Background()
+
+	// Without a default channel, the notifications that have no other
+	// destination go to the webhook.
+	notifier := NewNotifier(
+		WithWebhook(server.URL),
+		WithAPI(NewAPI(server.APIURL(), slacktest.Token), ""),
+	)
+	posted, err := notifier.Notify(ctx, Notification{Message: Message{Text: "hello"}, User: "@U012AB3CD"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posted != (Posted{}) {
+		t.Errorf("expected no Web API message, got %+v", posted)
+	}
+	// Replies to webhook messages are posted to the webhook, and the
+	// updates are ignored.
+	if _, err := notifier.Notify(ctx, Notification{Message: Message{Text: "reply"}, Thread: posted}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := notifier.Update(ctx, posted, Message{Text: "updated"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := server.MessagesIn(""); len(got) != 2 || got[0].Text != "hello" {
+		t.Errorf("expected two messages on the webhook, got %+v", got)
+	}
+
+	if _, err := NewNotifier().Notify(ctx, Notification{Message: Message{Text: "hello"}}); !errors.Is(err, ErrNoDestination) {
+		t.Errorf("expected %v, got %v", ErrNoDestination, err)
+	}
+}
+
+func TestNotifierDryRun(t *testing.T) {
+	server := slacktest.NewServer(t)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	mutation.SetDryRun(true)
+	mutation.SetOutput(&buf)
+	t.Cleanup(func() {
+		mutation.SetDryRun(false)
+		mutation.SetOutput(os.Stdout)
+	})
+
+	notifier := NewNotifier(
+		WithWebhook(server.URL),
+		WithAPI(NewAPI(server.APIURL(), slacktest.Token), "C0TEAM0001"),
+		WithDirectMessages(true),
+	)
+	for _, notification := range []Notification{
+		{Message: Message{Text: "hello"}, User: "@U012AB3CD"},
+		{Message: Message{Text: "hello"}},
+	} {
+		if _, err := notifier.Notify(ctx, notification); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := server.Requests(); got != 0 {
+		t.Errorf("expected no request to Slack, got %d", got)
+	}
+	for _, action := range []string{"slack.open_conversation", "slack.post_message"} {
+		if !strings.Contains(buf.String(), `"action":"`+action+`"`) {
+			t.Errorf("expected a %s record, got %q", action, buf.String())
+		}
+	}
+}
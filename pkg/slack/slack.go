@@ -6,24 +6,54 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/mutation"
 )
 
+// httpTimeout bounds the requests to Slack.
+const httpTimeout = 30 * time.Second
+
 type Client struct {
 	httpClient *http.Client
 }
 
 func New() Client {
-	return Client{httpClient: &http.Client{}}
+	return Client{httpClient: &http.Client{Timeout: httpTimeout}}
 }
 
+// Send posts a text message to the Slack hook. In dry-run mode, the message
+// is printed instead.
 func (c Client) Send(slackHook string, text string) error {
+	return c.SendMessage(slackHook, Message{Text: text})
+}
+
+// SendMessage posts the message to the Slack hook, with its blocks if any. In
+// dry-run mode, the message is printed instead.
+func (c Client) SendMessage(slackHook string, message Message) error {
+	var data any = message.Text
+	if len(message.Blocks) > 0 {
+		data = map[string]any{"text": message.Text, "blocks": message.Blocks}
+	}
+	return mutation.Apply(mutation.Record{
+		Action: "slack.send",
+		Target: "webhook",
+		Data:   data,
+	}, func() error {
+		return c.send(slackHook, message)
+	})
+}
+
+func (c Client) send(slackHook string, message Message) error {
 	var msg bytes.Buffer
 	err := json.NewEncoder(&msg).Encode(struct {
-		LinkNames bool   `json:"link_names"`
-		Text      string `json:"text"`
+		LinkNames bool    `json:"link_names"`
+		Text      string  `json:"text"`
+		Blocks    []Block `json:"blocks,omitempty"`
 	}{
 		LinkNames: true,
-		Text:      text,
+		Text:      message.Text,
+		Blocks:    message.Blocks,
 	})
 	if err != nil {
 		return fmt.Errorf("error marshalling the message payload: %w", err)
@@ -49,7 +79,3 @@ func (c Client) Send(slackHook string, text string) error {
 
 	return nil
 }
-
-func Link(text, url string) string {
-	return "<" + text + "|" + url + ">"
-}
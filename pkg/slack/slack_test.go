@@ -0,0 +1,54 @@
+package slack
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/shiftstack/bugwatcher/pkg/slacktest"
+)
+
+func TestSend(t *testing.T) {
+	server := slacktest.NewServer(t)
+
+	text := "<@U012AB3CD> please triage: " + Link("https://redhat.atlassian.net/browse/OCPBUGS-1", "OCPBUGS-1")
+	if err := New().Send(server.URL, text); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(messages))
+	}
+	m := messages[0]
+	if !m.LinkNames {
+		t.Error("expected link_names to be set")
+	}
+	if !m.HasMention("@U012AB3CD") {
+		t.Errorf("expected a mention of @U012AB3CD, got %q", m.Mentions)
+	}
+	if want := (slacktest.Link{URL: "https://redhat.atlassian.net/browse/OCPBUGS-1", Text: "OCPBUGS-1"}); len(m.Links) != 1 || m.Links[0] != want {
+		t.Errorf("expected the link %+v, got %+v", want, m.Links)
+	}
+}
+
+func TestSendFailure(t *testing.T) {
+	for _, status := range [...]int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := slacktest.NewServer(t)
+			server.FailNext(status, 1)
+
+			if err := New().Send(server.URL, "hello"); err == nil {
+				t.Fatal("expected an error")
+			}
+			if err := New().Send(server.URL, "hello again"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(server.Messages()); got != 1 {
+				t.Errorf("expected one message, got %d", got)
+			}
+			if got := server.Requests(); got != 2 {
+				t.Errorf("expected two requests, got %d", got)
+			}
+		})
+	}
+}
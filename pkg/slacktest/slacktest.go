@@ -0,0 +1,425 @@
+// Package slacktest provides a Slack incoming webhook, a subset of the Slack
+// Web API and the response URL of the interactions, which record the messages
+// they receive, for tests.
+package slacktest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Token is the bot token accepted by the Web API of the server.
+const Token = "xoxb-slacktest"
+
+// Message is a message posted to the webhook or with the Web API.
+type Message struct {
+	// Channel is the ID of the channel of the message, or empty for the
+	// webhook. The direct message channel with a user is "D" followed by
+	// the user ID.
+	Channel string
+	// TS is the timestamp identifying the message in its channel, or empty
+	// for the webhook.
+	TS string
+	// ThreadTS is the timestamp of the message replied to, if any.
+	ThreadTS string
+	// Updates is the number of times the message was updated.
+	Updates int
+
+	// Text is the text of the message, in Slack mrkdwn.
+	Text string
+	// LinkNames is the link_names flag of the payload.
+	LinkNames bool
+	// Blocks are the Block Kit blocks of the message, as decoded from
+	// JSON.
+	Blocks []map[string]any
+
+	// Mentions are the users and groups mentioned in the text, as written
+	// between angle brackets, e.g. "@U012AB3CD" or "!subteam^SKW6QC31Q".
+	Mentions []string
+	// Links are the links in the text.
+	Links []Link
+}
+
+// Response is a message sent to the response URL of an interaction.
+type Response struct {
+	Message
+	// ReplaceOriginal is set when the response replaces the message the
+	// interaction comes from.
+	ReplaceOriginal bool
+}
+
+// Link is a link in the text of a message.
+type Link struct {
+	URL  string
+	Text string
+}
+
+// BlockTexts returns the texts of the blocks, in order: the texts of the
+// sections and of their fields, and the elements of the context blocks.
+func (m Message) BlockTexts() []string {
+	var texts []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if text, ok := v["text"].(string); ok {
+				texts = append(texts, text)
+			}
+			for _, key := range [...]string{"text", "fields", "elements"} {
+				walk(v[key])
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	for _, block := range m.Blocks {
+		walk(block)
+	}
+	return texts
+}
+
+// HasMention reports whether the message mentions the user or group, e.g.
+// "@U012AB3CD".
+func (m Message) HasMention(id string) bool {
+	return slices.Contains(m.Mentions, id)
+}
+
+// Server is a fake Slack incoming webhook and Web API. Its URL is the webhook
+// URL; the Web API is served at APIURL, and accepts the bot token Token. The
+// responses to the interactions are sent to ResponseURL.
+type Server struct {
+	*httptest.Server
+
+	t testing.TB
+
+	mu        sync.Mutex
+	messages  []Message
+	responses []Response
+	failures  []int
+	requests  int
+	// ts is the timestamp of the last message posted with the Web API.
+	ts int
+}
+
+// NewServer starts a webhook and Web API. The server is closed at the end of
+// the test.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{t: t}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/chat.postMessage", s.api(s.postMessage))
+	mux.HandleFunc("/api/chat.update", s.api(s.update))
+	mux.HandleFunc("/api/conversations.open", s.api(s.openConversation))
+	mux.HandleFunc("/actions/", s.respond)
+	mux.HandleFunc("/", s.handle)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// APIURL returns the base URL of the Web API.
+func (s *Server) APIURL() string {
+	return s.URL + "/api/"
+}
+
+// ResponseURL returns the response URL of the interactions.
+func (s *Server) ResponseURL() string {
+	return s.URL + "/actions/T012AB3CD/1/slacktest"
+}
+
+// FailNext makes the next n requests fail with the given HTTP status code,
+// e.g. http.StatusTooManyRequests or http.StatusInternalServerError. The
+// failed requests are not recorded as messages.
+func (s *Server) FailNext(status, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// Messages returns the messages received so far, in order.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+// Responses returns the responses to the interactions received so far, in
+// order.
+func (s *Server) Responses() []Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.responses)
+}
+
+// MessagesTo returns the messages mentioning the user or group.
+func (s *Server) MessagesTo(id string) []Message {
+	var messages []Message
+	for _, m := range s.Messages() {
+		if m.HasMention(id) {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// MessagesIn returns the messages posted to the channel, e.g. "C012AB3CD" or
+// "DU012AB3CD" for the direct messages with U012AB3CD.
+func (s *Server) MessagesIn(channel string) []Message {
+	var messages []Message
+	for _, m := range s.Messages() {
+		if m.Channel == channel {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// Requests returns the number of requests received, including the failed
+// ones.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// fail responds with the next failure set with FailNext, if any, and reports
+// whether it did.
+func (s *Server) fail(w http.ResponseWriter) bool {
+	if len(s.failures) == 0 {
+		return false
+	}
+	status := s.failures[0]
+	s.failures = s.failures[1:]
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", strconv.Itoa(1))
+	}
+	http.Error(w, http.StatusText(status), status)
+	return true
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	if s.fail(w) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid_method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+	var payload struct {
+		Text      string           `json:"text"`
+		LinkNames bool             `json:"link_names"`
+		Blocks    []map[string]any `json:"blocks"`
+	}
+	if err := json.Unmarshal(b, &payload); err != nil || payload.Text == "" {
+		// Slack responds with the same error to a payload without text.
+		// Messages with blocks may omit the text, but bugwatcher always
+		// sends it as the fallback.
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+
+	m := Parse(payload.Text, payload.LinkNames)
+	m.Blocks = payload.Blocks
+	s.messages = append(s.messages, m)
+	io.WriteString(w, "ok")
+}
+
+func (s *Server) respond(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	if s.fail(w) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid_method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		Text            string           `json:"text"`
+		Blocks          []map[string]any `json:"blocks"`
+		ReplaceOriginal bool             `json:"replace_original"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Text == "" {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+
+	m := Parse(payload.Text, false)
+	m.Blocks = payload.Blocks
+	s.responses = append(s.responses, Response{Message: m, ReplaceOriginal: payload.ReplaceOriginal})
+	io.WriteString(w, "ok")
+}
+
+// api returns the handler of a Web API method. Like Slack, it answers errors
+// with the status 200 and "ok": false, except for the failures set with
+// FailNext.
+func (s *Server) api(method func(payload map[string]any) (map[string]any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+
+		if s.fail(w) {
+			return
+		}
+
+		response, err := func() (map[string]any, error) {
+			if r.Method != http.MethodPost {
+				return nil, errors.New("method_not_supported_for_channel_type")
+			}
+			switch r.Header.Get("Authorization") {
+			case "":
+				return nil, errors.New("not_authed")
+			case "Bearer " + Token:
+			default:
+				return nil, errors.New("invalid_auth")
+			}
+			if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				return nil, errors.New("invalid_form_data")
+			}
+			payload := make(map[string]any)
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				return nil, errors.New("invalid_json")
+			}
+			return method(payload)
+		}()
+		if err != nil {
+			response = map[string]any{"ok": false, "error": err.Error()}
+		} else {
+			response["ok"] = true
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			s.t.Errorf("slacktest: error encoding the response: %v", err)
+		}
+	}
+}
+
+func (s *Server) postMessage(payload map[string]any) (map[string]any, error) {
+	channel, _ := payload["channel"].(string)
+	text, _ := payload["text"].(string)
+	threadTS, _ := payload["thread_ts"].(string)
+	linkNames, _ := payload["link_names"].(bool)
+	switch {
+	case channel == "":
+		return nil, errors.New("channel_not_found")
+	case text == "":
+		return nil, errors.New("no_text")
+	case threadTS != "" && s.find(channel, threadTS) == nil:
+		return nil, errors.New("thread_not_found")
+	}
+
+	s.ts++
+	m := Parse(text, linkNames)
+	m.Blocks = blocks(payload)
+	m.Channel = channel
+	m.TS = fmt.Sprintf("1700000000.%06d", s.ts)
+	m.ThreadTS = threadTS
+	s.messages = append(s.messages, m)
+	return map[string]any{"channel": channel, "ts": m.TS}, nil
+}
+
+func (s *Server) update(payload map[string]any) (map[string]any, error) {
+	channel, _ := payload["channel"].(string)
+	ts, _ := payload["ts"].(string)
+	text, _ := payload["text"].(string)
+	linkNames, _ := payload["link_names"].(bool)
+	m := s.find(channel, ts)
+	switch {
+	case m == nil:
+		return nil, errors.New("message_not_found")
+	case text == "":
+		return nil, errors.New("no_text")
+	}
+
+	updated := Parse(text, linkNames)
+	updated.Blocks = m.Blocks
+	if _, ok := payload["blocks"]; ok {
+		// Like Slack, the blocks are kept unless replaced.
+		updated.Blocks = blocks(payload)
+	}
+	updated.Channel, updated.TS, updated.ThreadTS = m.Channel, m.TS, m.ThreadTS
+	updated.Updates = m.Updates + 1
+	*m = updated
+	return map[string]any{"channel": channel, "ts": ts}, nil
+}
+
+func (s *Server) openConversation(payload map[string]any) (map[string]any, error) {
+	users, _ := payload["users"].(string)
+	if users == "" || strings.Contains(users, ",") {
+		// Group conversations are not supported.
+		return nil, errors.New("user_not_found")
+	}
+	return map[string]any{"channel": map[string]any{"id": "D" + users}}, nil
+}
+
+// blocks returns the blocks of the Web API payload.
+func blocks(payload map[string]any) []map[string]any {
+	list, _ := payload["blocks"].([]any)
+	var blocks []map[string]any
+	for _, block := range list {
+		if block, ok := block.(map[string]any); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// find returns the message with the timestamp in the channel, or nil.
+func (s *Server) find(channel, ts string) *Message {
+	for i := range s.messages {
+		if s.messages[i].Channel == channel && s.messages[i].TS == ts {
+			return &s.messages[i]
+		}
+	}
+	return nil
+}
+
+// special matches the mentions and links of mrkdwn, e.g. "<@U012AB3CD>" and
+// "<https://example.com|example>".
+var special = regexp.MustCompile(`<([^<>|]+)(?:\|([^<>]*))?>`)
+
+// Parse extracts the mentions and the links of the text.
+func Parse(text string, linkNames bool) Message {
+	m := Message{Text: text, LinkNames: linkNames}
+	for _, match := range special.FindAllStringSubmatch(text, -1) {
+		switch target := match[1]; target[0] {
+		case '@', '!', '#':
+			m.Mentions = append(m.Mentions, target)
+		default:
+			m.Links = append(m.Links, Link{URL: target, Text: match[2]})
+		}
+	}
+	return m
+}
@@ -0,0 +1,174 @@
+package team
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// holidayCalendars contains the public holidays by calendar name. Each file
+// maps ISO dates to the name of the holiday.
+//
+//go:embed holidays/*.yaml
+var holidayCalendars embed.FS
+
+// defaultDayEnd is the local time after which a person who set their
+// timezone is not considered available anymore, unless they set
+// working_hours_end.
+const defaultDayEnd = 18 * time.Hour
+
+// defaultWorkingDays are the working days of a person who set their timezone
+// but not their working_days.
+var defaultWorkingDays = []string{"Mon", "Tue", "Wed", "Thu", "Fri"}
+
+// HolidayCalendar returns the public holidays of the calendar with the given
+// name, indexed by date in the form YYYY-MM-DD.
+func HolidayCalendar(name string) (map[string]string, error) {
+	f, err := holidayCalendars.Open("holidays/" + name + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown holiday calendar %q", name)
+	}
+	defer f.Close()
+
+	var holidays map[string]string
+	if err := yaml.NewDecoder(f).Decode(&holidays); err != nil {
+		return nil, fmt.Errorf("error decoding holiday calendar %q: %w", name, err)
+	}
+	return holidays, nil
+}
+
+// HolidayCalendarCovers reports whether the holiday calendar with the given
+// name lists the public holidays of the year. The embedded calendars need to
+// be extended every year.
+func HolidayCalendarCovers(name string, year int) (bool, error) {
+	holidays, err := HolidayCalendar(name)
+	if err != nil {
+		return false, err
+	}
+	prefix := fmt.Sprintf("%04d-", year)
+	for date := range holidays {
+		if strings.HasPrefix(date, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// availability holds the parsed form of the working time settings of a
+// Person.
+type availability struct {
+	location    *time.Location
+	workingDays [7]bool
+	// dayEnd is the local time when the working day ends, or zero if the
+	// working hours are not restricted.
+	dayEnd   time.Duration
+	holidays map[string]string
+}
+
+// parseAvailability validates the working time settings of the person, and
+// applies the defaults. A person who sets none of timezone, working_days and
+// working_hours_end is available every day at any hour; setting the timezone
+// applies the default working days and hours.
+func (p *Person) parseAvailability() error {
+	p.availability.location = time.UTC
+	if p.Timezone != "" {
+		location, err := time.LoadLocation(p.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", p.Timezone, err)
+		}
+		p.availability.location = location
+	}
+
+	workingDays := p.WorkingDays
+	if len(workingDays) == 0 && p.Timezone != "" {
+		workingDays = defaultWorkingDays
+	}
+	for _, day := range workingDays {
+		weekday, err := parseWeekday(day)
+		if err != nil {
+			return err
+		}
+		p.availability.workingDays[weekday] = true
+	}
+	if len(workingDays) == 0 {
+		p.availability.workingDays = everyDay
+	}
+
+	if p.Timezone != "" {
+		p.availability.dayEnd = defaultDayEnd
+	}
+	if p.WorkingHoursEnd != "" {
+		end, err := time.Parse("15:04", p.WorkingHoursEnd)
+		if err != nil {
+			return fmt.Errorf("invalid working_hours_end %q: expected HH:MM", p.WorkingHoursEnd)
+		}
+		p.availability.dayEnd = time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute
+	}
+
+	if p.Holidays != "" {
+		holidays, err := HolidayCalendar(p.Holidays)
+		if err != nil {
+			return err
+		}
+		p.availability.holidays = holidays
+	}
+
+	return nil
+}
+
+func parseWeekday(day string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(day, weekday.String()) || strings.EqualFold(day, weekday.String()[:3]) {
+			return weekday, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid working day %q", day)
+}
+
+// everyDay are the working days of the people who did not restrict them.
+var everyDay = [7]bool{true, true, true, true, true, true, true}
+
+// workingTime returns the parsed working time settings. The people not
+// obtained through Load are available every day at any hour.
+func (p Person) workingTime() availability {
+	if p.availability.location != nil {
+		return p.availability
+	}
+	return availability{location: time.UTC, workingDays: everyDay}
+}
+
+// Location returns the timezone of the person.
+func (p Person) Location() *time.Location {
+	return p.workingTime().location
+}
+
+// IsWorkingDay returns false if t falls on a non-working day or on a public
+// holiday in the person's timezone.
+func (p Person) IsWorkingDay(t time.Time) bool {
+	a := p.workingTime()
+	local := t.In(a.location)
+
+	if !a.workingDays[local.Weekday()] {
+		return false
+	}
+	if _, ok := a.holidays[local.Format(time.DateOnly)]; ok {
+		return false
+	}
+	return true
+}
+
+// hasDayEnded returns true if t is past the end of the working day in the
+// person's timezone. It is always false if the working hours are not
+// restricted.
+func (p Person) hasDayEnded(t time.Time) bool {
+	a := p.workingTime()
+	if a.dayEnd == 0 {
+		return false
+	}
+	local := t.In(a.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, a.location)
+	return local.Sub(midnight) >= a.dayEnd
+}
@@ -0,0 +1,64 @@
+package team
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const availabilityPeople = `
+- kerberos: unrestricted
+- kerberos: paris
+  timezone: Europe/Paris
+  holidays: fr
+- kerberos: weekdays
+  working_days: [Mon, Tue, Wed, Thu, Fri]
+- kerberos: hours
+  working_hours_end: "20:00"
+`
+
+func TestIsAvailable(t *testing.T) {
+	people, err := Load(strings.NewReader(availabilityPeople))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unrestricted, paris, weekdays, hours := people[0], people[1], people[2], people[3]
+	parisTZ := paris.Location()
+
+	for _, tc := range [...]struct {
+		name      string
+		person    Person
+		t         time.Time
+		available bool
+	}{
+		{"no settings, evening", unrestricted, time.Date(2026, 10, 21, 23, 0, 0, 0, time.UTC), true},
+		{"no settings, weekend", unrestricted, time.Date(2026, 10, 24, 12, 0, 0, 0, time.UTC), true},
+		{"timezone, working hours", paris, time.Date(2026, 10, 21, 17, 59, 0, 0, parisTZ), true},
+		{"timezone, after the default end", paris, time.Date(2026, 10, 21, 18, 0, 0, 0, parisTZ), false},
+		{"timezone, weekend", paris, time.Date(2026, 10, 24, 12, 0, 0, 0, parisTZ), false},
+		{"timezone, public holiday", paris, time.Date(2026, 11, 11, 12, 0, 0, 0, parisTZ), false},
+		{"working days, evening", weekdays, time.Date(2026, 10, 21, 23, 0, 0, 0, time.UTC), true},
+		{"working days, weekend", weekdays, time.Date(2026, 10, 24, 12, 0, 0, 0, time.UTC), false},
+		{"working hours, before the end", hours, time.Date(2026, 10, 24, 19, 0, 0, 0, time.UTC), true},
+		{"working hours, after the end", hours, time.Date(2026, 10, 21, 20, 30, 0, 0, time.UTC), false},
+		{"not loaded", Person{}, time.Date(2026, 10, 24, 23, 0, 0, 0, time.UTC), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if available := tc.person.IsAvailable(tc.t); available != tc.available {
+				t.Errorf("expected IsAvailable(%s) to be %t, got %t", tc.t, tc.available, available)
+			}
+		})
+	}
+}
+
+func TestHolidayCalendarCovers(t *testing.T) {
+	if covered, err := HolidayCalendarCovers("fr", 2026); err != nil || !covered {
+		t.Errorf("expected 2026 to be covered, got %t, %v", covered, err)
+	}
+	if covered, err := HolidayCalendarCovers("fr", 2040); err != nil || covered {
+		t.Errorf("expected 2040 not to be covered, got %t, %v", covered, err)
+	}
+	if _, err := HolidayCalendarCovers("atlantis", 2026); err == nil {
+		t.Errorf("expected an error for an unknown calendar")
+	}
+}
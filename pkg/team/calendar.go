@@ -0,0 +1,126 @@
+package team
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shiftstack/bugwatcher/pkg/ical"
+)
+
+// fetchCalendar reads an iCalendar feed from a local path or from an HTTP(S)
+// or webcal URL.
+func fetchCalendar(client *http.Client, source string) ([]ical.Event, error) {
+	var r io.Reader
+	switch {
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"), strings.HasPrefix(source, "webcal://"):
+		res, err := client.Get(strings.Replace(source, "webcal://", "https://", 1))
+		if err != nil {
+			return nil, fmt.Errorf("error fetching the calendar: %w", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %q fetching the calendar", res.Status)
+		}
+
+		var body bytes.Buffer
+		if _, err := io.Copy(&body, res.Body); err != nil {
+			return nil, fmt.Errorf("error fetching the calendar: %w", err)
+		}
+		r = &body
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("error opening the calendar: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	return ical.Parse(r, time.UTC)
+}
+
+// recurrenceHorizon is how far in the future the recurring events are
+// expanded.
+const recurrenceHorizon = 365 * 24 * time.Hour
+
+// occurrences returns the occurrences of the events that are not cancelled.
+// The recurring events are only expanded from now on. The recurrence rules
+// that cannot be expanded are logged, and only their first occurrence is
+// kept.
+func occurrences(events []ical.Event, source string) []ical.Event {
+	from := time.Now()
+	until := from.Add(recurrenceHorizon)
+	var occurrences []ical.Event
+	for _, event := range events {
+		if event.Status == "CANCELLED" {
+			continue
+		}
+		o, err := event.Occurrences(from, until)
+		if err != nil {
+			log.Printf("WARNING: calendar %q: event %q: %v: only its first occurrence is considered", source, event.Summary, err)
+		}
+		occurrences = append(occurrences, o...)
+	}
+	return occurrences
+}
+
+// leaveFromEvent converts an event of the calendar source to a leave range.
+// All-day events are anchored to midnight in the given location.
+func leaveFromEvent(event ical.Event, source string, location *time.Location) Leave {
+	if !event.AllDay {
+		return Leave{Start: event.Start, End: event.End, Calendar: source}
+	}
+	return Leave{
+		Start:    time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day(), 0, 0, 0, 0, location),
+		End:      time.Date(event.End.Year(), event.End.Month(), event.End.Day(), 0, 0, 0, 0, location),
+		Calendar: source,
+	}
+}
+
+// calendarLeave returns the leave ranges of the events in the calendar.
+// Cancelled events are ignored.
+func calendarLeave(client *http.Client, source string, location *time.Location) ([]Leave, error) {
+	events, err := fetchCalendar(client, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var leave []Leave
+	for _, event := range occurrences(events, source) {
+		leave = append(leave, leaveFromEvent(event, source, location))
+	}
+	return leave, nil
+}
+
+// addTeamCalendarLeave adds the events of the team calendar to the leave of
+// the matching people.
+func addTeamCalendarLeave(client *http.Client, source string, people []Person) error {
+	events, err := fetchCalendar(client, source)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range occurrences(events, source) {
+		addresses := event.Attendees
+		if len(addresses) == 0 && event.Organizer != "" {
+			addresses = []string{event.Organizer}
+		}
+
+		for _, address := range addresses {
+			username, _, _ := strings.Cut(address, "@")
+			for i := range people {
+				if people[i].Kerberos != "" && strings.EqualFold(people[i].Kerberos, username) {
+					people[i].addLeave(leaveFromEvent(event, source, people[i].workingTime().location))
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package team
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendarPeople = `
+- kerberos: user1
+  bug_triage: true
+  timezone: Europe/Paris
+  calendar: testdata/user1.ics
+- kerberos: user2
+  bug_triage: true
+  timezone: America/New_York
+`
+
+func TestLoadCalendars(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	people, err := Load(strings.NewReader(calendarPeople), WithTeamCalendar("testdata/team.ics"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tc := range [...]struct {
+		name      string
+		person    Person
+		t         time.Time
+		available bool
+	}{
+		{"all-day leave", people[0], time.Date(2026, 10, 19, 10, 0, 0, 0, paris), false},
+		{"all-day leave, last day", people[0], time.Date(2026, 10, 20, 17, 0, 0, 0, paris), false},
+		{"after all-day leave", people[0], time.Date(2026, 10, 21, 10, 0, 0, 0, paris), true},
+		{"during partial-day leave", people[0], time.Date(2026, 10, 22, 15, 0, 0, 0, paris), false},
+		{"before partial-day leave", people[0], time.Date(2026, 10, 22, 11, 0, 0, 0, paris), true},
+		{"cancelled leave", people[0], time.Date(2026, 10, 23, 10, 0, 0, 0, paris), true},
+		{"team calendar, organizer", people[0], time.Date(2026, 10, 27, 10, 0, 0, 0, paris), false},
+		{"team calendar, after organizer leave", people[0], time.Date(2026, 10, 27, 14, 0, 0, 0, paris), true},
+		{"team calendar, attendee", people[1], time.Date(2026, 10, 26, 10, 0, 0, 0, newYork), false},
+		{"team calendar, organizer is not an attendee", people[1], time.Date(2026, 10, 27, 10, 0, 0, 0, newYork), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if available := tc.person.IsAvailable(tc.t); available != tc.available {
+				t.Errorf("expected IsAvailable(%s) to be %t, got %t", tc.t, tc.available, available)
+			}
+		})
+	}
+}
+
+func TestLoadCalendarURL(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	people, err := Load(strings.NewReader(`
+- kerberos: user1
+  calendar: `+server.URL+`/user1.ics
+`), WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := len(people[0].Leave); n != 2 {
+		t.Errorf("expected 2 leave ranges, got %d: %v", n, people[0].Leave)
+	}
+}
+
+func TestLoadCalendarDuplicate(t *testing.T) {
+	people, err := Load(strings.NewReader(`
+- kerberos: user1
+  calendar: testdata/user1.ics
+  leave:
+  - start: 2026-10-19
+    end: 2026-10-20
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	leave := people[0].Leave
+	if len(leave) != 2 || leave[0].Calendar != "" || leave[1].Calendar != "testdata/user1.ics" {
+		t.Errorf("expected the leave from YAML and the partial-day leave from the calendar, got %+v", leave)
+	}
+}
+
+func TestLoadCalendarNotFound(t *testing.T) {
+	people, err := Load(strings.NewReader(`
+- kerberos: user1
+  calendar: testdata/missing.ics
+  leave:
+  - start: 2026-10-19
+    end: 2026-10-19
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(people[0].Leave); n != 1 {
+		t.Errorf("expected the leave from YAML to be kept, got %v", people[0].Leave)
+	}
+
+	if _, err := Load(strings.NewReader(`- kerberos: user1`), WithTeamCalendar("testdata/missing.ics")); err == nil {
+		t.Errorf("expected an error for a missing team calendar")
+	}
+}
+
+func TestLoadRecurringCalendar(t *testing.T) {
+	people, err := Load(strings.NewReader(`
+- kerberos: user1
+  calendar: testdata/recurring.ics
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tc := range [...]struct {
+		name      string
+		t         time.Time
+		available bool
+	}{
+		{"first occurrence", time.Date(2026, 10, 23, 10, 0, 0, 0, time.UTC), false},
+		{"second occurrence", time.Date(2026, 10, 30, 10, 0, 0, 0, time.UTC), false},
+		{"excluded occurrence", time.Date(2026, 11, 6, 10, 0, 0, 0, time.UTC), true},
+		{"last occurrence", time.Date(2026, 11, 13, 10, 0, 0, 0, time.UTC), false},
+		{"after the last occurrence", time.Date(2026, 11, 20, 10, 0, 0, 0, time.UTC), true},
+		{"between occurrences", time.Date(2026, 10, 28, 10, 0, 0, 0, time.UTC), true},
+		{"unsupported rule, first occurrence", time.Date(2026, 10, 28, 15, 30, 0, 0, time.UTC), false},
+		{"unsupported rule, next occurrence", time.Date(2026, 11, 25, 15, 30, 0, 0, time.UTC), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if available := people[0].IsAvailable(tc.t); available != tc.available {
+				t.Errorf("expected IsAvailable(%s) to be %t, got %t", tc.t, tc.available, available)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package team
+
+import (
+	"net/http"
+	"time"
+)
+
+// LoadOption configures Load.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	teamCalendar string
+	client       *http.Client
+	strict       bool
+}
+
+// defaultHTTPClient fetches the iCalendar feeds when no client is set with
+// WithHTTPClient. Its timeout keeps a stalled calendar server from blocking the
+// commands.
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+func (o loadOptions) httpClient() *http.Client {
+	if o.client == nil {
+		return defaultHTTPClient
+	}
+	return o.client
+}
+
+// WithTeamCalendar sets the path or the URL of a shared iCalendar feed. Each
+// event is added to the leave of the people whose Kerberos matches the email
+// address of one of its attendees. Events without attendees are matched
+// against their organizer instead.
+func WithTeamCalendar(source string) LoadOption {
+	return func(o *loadOptions) {
+		o.teamCalendar = source
+	}
+}
+
+// Strict makes Load fail on unknown YAML keys.
+func Strict() LoadOption {
+	return func(o *loadOptions) {
+		o.strict = true
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to fetch the iCalendar feeds.
+func WithHTTPClient(client *http.Client) LoadOption {
+	return func(o *loadOptions) {
+		o.client = client
+	}
+}
@@ -3,18 +3,30 @@ package team
 import (
 	"fmt"
 	"io"
+	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-// ID of @ocp-openstack-team
-// https://api.slack.com/reference/surfaces/formatting#mentioning-groups
-const TeamSlackId = "!subteam^SKW6QC31Q"
-
+// Leave is an absence. Start is inclusive and End is exclusive. In the YAML
+// roster, an End with no time of day is the last day of the absence.
 type Leave struct {
-	Start time.Time
-	End   time.Time
+	Start time.Time `yaml:"start"`
+	End   time.Time `yaml:"end"`
+
+	// Calendar is the iCalendar feed the leave was imported from. It is
+	// empty for the leave declared in YAML.
+	Calendar string `yaml:"-"`
+}
+
+// Expertise is a Jira component a person knows about. The weight expresses
+// how much they know; it defaults to 1.
+type Expertise struct {
+	Component string  `yaml:"name"`
+	Weight    float64 `yaml:"weight,omitempty"`
 }
 
 type Person struct {
@@ -24,33 +36,142 @@ type Person struct {
 	JiraAccountID string `yaml:"jira_account_id"`
 	Slack         string `yaml:"slack_id"`
 
-	BugTriage bool    `yaml:"bug_triage,omitempty"`
-	leave     []Leave `yaml:"leave,omitempty"`
+	BugTriage  bool        `yaml:"bug_triage,omitempty"`
+	Components []Expertise `yaml:"components,omitempty"`
+	Leave      []Leave     `yaml:"leave,omitempty"`
+
+	// Calendar is the path or the URL of an iCalendar feed containing the
+	// absences of the person. Its events are added to Leave.
+	Calendar string `yaml:"calendar,omitempty"`
+
+	// Timezone is an IANA time zone name. Defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+	// WorkingDays lists the days of the week the person works, e.g.
+	// "Mon". Defaults to Monday to Friday if Timezone is set, and to every
+	// day otherwise.
+	WorkingDays []string `yaml:"working_days,omitempty"`
+	// WorkingHoursEnd is the local time when the working day ends, in the
+	// form HH:MM. Defaults to 18:00 if Timezone is set; otherwise the
+	// working hours are not restricted.
+	WorkingHoursEnd string `yaml:"working_hours_end,omitempty"`
+	// Holidays is the name of the public holiday calendar that applies to
+	// the person, e.g. "fr". See the holidays directory.
+	Holidays string `yaml:"holidays,omitempty"`
+
+	availability availability
+}
+
+// ComponentWeight returns the weight of the person's expertise in the given
+// Jira component, or zero if they don't know about it.
+func (p Person) ComponentWeight(component string) float64 {
+	for _, expertise := range p.Components {
+		if expertise.Component == component {
+			return expertise.Weight
+		}
+	}
+	return 0
 }
 
+// IsAvailable returns false if, at the time t, the person is on leave, it is
+// not a working day for them, or their working day has ended.
 func (p Person) IsAvailable(t time.Time) bool {
-	for _, leave := range p.leave {
+	for _, leave := range p.Leave {
 		if leave.End.After(t) && leave.Start.Before(t) {
 			return false
 		}
 	}
-	return true
+	return p.IsWorkingDay(t) && !p.hasDayEnded(t)
 }
 
-func Load(peopleYAML io.Reader) ([]Person, error) {
+// addLeave adds the leave ranges that the person does not already have. The
+// same absence is often both declared in YAML and imported from a calendar.
+func (p *Person) addLeave(leave ...Leave) {
+	for _, l := range leave {
+		if !slices.ContainsFunc(p.Leave, func(other Leave) bool {
+			return other.Start.Equal(l.Start) && other.End.Equal(l.End)
+		}) {
+			p.Leave = append(p.Leave, l)
+		}
+	}
+}
+
+// Load decodes the people from YAML. The iCalendar feeds of each person, and
+// the team calendar if set with WithTeamCalendar, are fetched and added to
+// their leave. The calendars of the people that cannot be loaded are logged
+// and skipped; the team calendar must load.
+func Load(peopleYAML io.Reader, opts ...LoadOption) ([]Person, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var people []Person
-	if err := yaml.NewDecoder(peopleYAML).Decode(&people); err != nil {
+	decoder := yaml.NewDecoder(peopleYAML)
+	decoder.KnownFields(options.strict)
+	if err := decoder.Decode(&people); err != nil {
 		return nil, fmt.Errorf("error decoding people: %w", err)
 	}
 
 	for i := range people {
 		// user handles need a prepended `@` when mentioned in the chat
 		people[i].Slack = "@" + people[i].Slack
+
+		if err := people[i].parseAvailability(); err != nil {
+			return nil, fmt.Errorf("error decoding person %q: %w", people[i].Kerberos, err)
+		}
+
+		for j, leave := range people[i].Leave {
+			if leave.End.Equal(leave.End.Truncate(24 * time.Hour)) {
+				people[i].Leave[j].End = leave.End.AddDate(0, 0, 1)
+			}
+		}
+
+		for j := range people[i].Components {
+			if people[i].Components[j].Weight == 0 {
+				people[i].Components[j].Weight = 1
+			}
+		}
+
+		if people[i].Calendar != "" {
+			// An unreachable calendar must not prevent the whole
+			// team from being loaded: the leave declared in YAML
+			// still applies.
+			leave, err := calendarLeave(options.httpClient(), people[i].Calendar, people[i].workingTime().location)
+			if err != nil {
+				log.Printf("WARNING: error loading the calendar of %q: %v", people[i].Kerberos, err)
+			}
+			people[i].addLeave(leave...)
+		}
+	}
+
+	if options.teamCalendar != "" {
+		if err := addTeamCalendarLeave(options.httpClient(), options.teamCalendar, people); err != nil {
+			return nil, fmt.Errorf("error loading the team calendar: %w", err)
+		}
 	}
 
 	return people, nil
 }
 
+// ExpertsOf returns the people with the highest expertise weight in the given
+// Jira component. The returned slice is empty if nobody knows about it.
+func ExpertsOf(people []Person, component string) []Person {
+	var (
+		experts []Person
+		best    float64
+	)
+	for i := range people {
+		switch w := people[i].ComponentWeight(component); {
+		case w > best:
+			experts = []Person{people[i]}
+			best = w
+		case w == best && w > 0:
+			experts = append(experts, people[i])
+		}
+	}
+	return experts
+}
+
 // PersonByJiraName returns the first person in the slice with the given Jira
 // name. The returned boolean is false if not found.
 func PersonByJiraName(people []Person, jiraName string) (Person, bool) {
@@ -92,3 +213,19 @@ func PersonByGithubHandle(people []Person, githubHandle string) (Person, bool) {
 	}
 	return Person{}, false
 }
+
+// PersonBySlackID returns the first person in the slice with the given Slack
+// user ID, with or without the leading "@". The returned boolean is false if
+// not found.
+func PersonBySlackID(people []Person, slackID string) (Person, bool) {
+	if slackID == "" {
+		return Person{}, false
+	}
+	slackID = "@" + strings.TrimPrefix(slackID, "@")
+	for i := range people {
+		if people[i].Slack == slackID {
+			return people[i], true
+		}
+	}
+	return Person{}, false
+}
@@ -0,0 +1,102 @@
+// Package workpool runs the processing of the issues with a bounded
+// concurrency.
+package workpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultConcurrency is the number of tasks run at once unless configured
+// otherwise.
+const DefaultConcurrency = 8
+
+// Task is a unit of work. The context is cancelled when the pool's context
+// is.
+type Task func(ctx context.Context) error
+
+// Pool runs tasks in at most a fixed number of goroutines. The zero value is
+// not usable: build pools with New.
+type Pool struct {
+	ctx   context.Context
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// New returns a pool running at most concurrency tasks at once. A
+// concurrency lower than 1 is treated as 1.
+func New(ctx context.Context, concurrency int) *Pool {
+	return &Pool{
+		ctx:   ctx,
+		slots: make(chan struct{}, max(concurrency, 1)),
+	}
+}
+
+// Go runs the task in a new goroutine as soon as a slot is free, blocking the
+// caller meanwhile. Once the context is done, Go returns immediately without
+// running the task.
+func (p *Pool) Go(task Task) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.ctx.Done():
+		return
+	}
+	// The context might have been cancelled while waiting for the slot.
+	if p.ctx.Err() != nil {
+		<-p.slots
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.wg.Done()
+		}()
+		if err := task(p.ctx); err != nil {
+			p.mu.Lock()
+			p.errs = append(p.errs, err)
+			p.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for the running tasks to return. It returns the errors of the
+// tasks joined together, and the context error if tasks were skipped because
+// the context was done.
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	errs := p.errs
+	if err := p.ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Results accumulates values produced by concurrent tasks. The zero value is
+// ready to use.
+type Results[T any] struct {
+	mu     sync.Mutex
+	values []T
+}
+
+// Add appends a value. It is safe to call from several goroutines.
+func (r *Results[T]) Add(v T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = append(r.values, v)
+}
+
+// Values returns the values added so far, in the order they were added.
+func (r *Results[T]) Values() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]T(nil), r.values...)
+}
@@ -0,0 +1,93 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolBoundsConcurrency(t *testing.T) {
+	pool := New(context.Background(), 3)
+
+	var running, maxRunning, done atomic.Int32
+	for range 20 {
+		pool.Go(func(context.Context) error {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			done.Add(1)
+			return nil
+		})
+	}
+	if err := pool.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := done.Load(); got != 20 {
+		t.Errorf("expected 20 tasks to run, got %d", got)
+	}
+	if got := maxRunning.Load(); got > 3 {
+		t.Errorf("expected at most 3 tasks at once, got %d", got)
+	}
+}
+
+func TestPoolJoinsErrors(t *testing.T) {
+	pool := New(context.Background(), 2)
+	errOdd := errors.New("odd")
+
+	var results Results[int]
+	for i := range 10 {
+		pool.Go(func(context.Context) error {
+			if i%2 == 1 {
+				return fmt.Errorf("task %d: %w", i, errOdd)
+			}
+			results.Add(i)
+			return nil
+		})
+	}
+	err := pool.Wait()
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("expected the task errors, got %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 5 {
+		t.Errorf("expected 5 errors, got %d", n)
+	}
+	if got := results.Values(); len(got) != 5 {
+		t.Errorf("expected 5 results, got %v", got)
+	}
+}
+
+func TestPoolHonorsTheContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := New(ctx, 1)
+
+	var ran atomic.Int32
+	pool.Go(func(ctx context.Context) error {
+		ran.Add(1)
+		cancel()
+		<-ctx.Done()
+		return nil
+	})
+	for range 5 {
+		pool.Go(func(context.Context) error {
+			ran.Add(1)
+			return nil
+		})
+	}
+
+	if err := pool.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+	if got := ran.Load(); got != 1 {
+		t.Errorf("expected the tasks not to run after the cancellation, %d ran", got)
+	}
+}